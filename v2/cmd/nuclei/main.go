@@ -168,19 +168,96 @@ on extensive configurability, massive extensibility and ease of use.`)
 
 	flagSet.CreateGroup("output", "Output",
 		flagSet.StringVarP(&options.Output, "output", "o", "", "output file to write found issues/vulnerabilities"),
+		flagSet.StringVarP(&options.JSONOutput, "json-output", "jo", "", "output file to write found issues/vulnerabilities in JSONL(ines) format"),
+		flagSet.StringVarP(&options.TextOutput, "text-output", "to", "", "output file to write found issues/vulnerabilities as text"),
 		flagSet.BoolVarP(&options.StoreResponse, "store-resp", "sresp", false, "store all request/response passed through nuclei to output directory"),
 		flagSet.StringVarP(&options.StoreResponseDir, "store-resp-dir", "srd", runner.DefaultDumpTrafficOutputFolder, "store all request/response passed through nuclei to custom directory"),
+		flagSet.IntVarP(&options.MaxOpenStoredFiles, "store-resp-max-open", "srmo", 64, "maximum number of stored request/response files kept open"),
+		flagSet.BoolVarP(&options.StoreResponseDateFolders, "store-resp-date", "srdt", false, "store request/response under a YYYY-MM-DD folder of the day they were found"),
+		flagSet.IntVarP(&options.MaxStoredResponsesPerKey, "store-resp-max", "srm", 0, "maximum number of request/response stored per host and template (0 for no limit)"),
 		flagSet.BoolVar(&options.Silent, "silent", false, "display findings only"),
 		flagSet.BoolVarP(&options.NoColor, "no-color", "nc", false, "disable output content coloring (ANSI escape codes)"),
+		flagSet.StringVarP(&options.ColorTheme, "color-theme", "ct", "default", "theme used to color the severities (default, high-contrast, monochrome)"),
 		flagSet.BoolVarP(&options.JSONL, "jsonl", "j", false, "write output in JSONL(ines) format"),
-		flagSet.BoolVarP(&options.JSONRequests, "include-rr", "irr", false, "include request/response pairs in the JSONL output (for findings only)"),
+		flagSet.StringVarP(&options.OutputFormat, "output-format", "of", "json", "format of the JSONL results written to stdout and the output file (json,ecs,cyclonedx)"),
+		flagSet.BoolVarP(&options.JSONRequests, "include-rr", "irr", false, "include request/response pairs in the JSONL and screen output (for findings only)"),
+		flagSet.IntVarP(&options.ScreenBodyLimit, "screen-body-limit", "sbl", 0, "maximum bytes of the request/response shown in the screen output (0 for no limit)"),
 		flagSet.BoolVarP(&options.NoMeta, "no-meta", "nm", false, "disable printing result metadata in cli output"),
+		flagSet.BoolVarP(&options.CompactOutput, "compact", "cpt", false, "print results as a single [template-id] [severity] matched-at line"),
 		flagSet.BoolVarP(&options.Timestamp, "timestamp", "ts", false, "enables printing timestamp in cli output"),
 		flagSet.StringVarP(&options.ReportingDB, "report-db", "rdb", "", "nuclei reporting database (always use this to persist report data)"),
 		flagSet.BoolVarP(&options.MatcherStatus, "matcher-status", "ms", false, "display match failure status"),
 		flagSet.StringVarP(&options.MarkdownExportDirectory, "markdown-export", "me", "", "directory to export results in markdown format"),
 		flagSet.StringVarP(&options.SarifExport, "sarif-export", "se", "", "file to export results in SARIF format"),
 		flagSet.StringVarP(&options.JSONExport, "json-export", "je", "", "file to export results in JSON format"),
+		flagSet.StringVarP(&options.SyslogTarget, "syslog", "slg", "", "syslog to write results to (local, udp://host:port, tcp://host:port)"),
+		flagSet.StringVarP(&options.SyslogPriority, "syslog-priority", "slp", "user.info", "facility.severity priority of the results written to syslog"),
+		flagSet.StringVarP(&options.JUnitExport, "junit-export", "jue", "", "file to export results as a JUnit XML report"),
+		flagSet.StringVar(&options.SQLiteOutput, "sqlite", "", "sqlite database to append results to"),
+		flagSet.BoolVar(&options.Stdout, "stdout", false, "write results to stdout (eg. to pipe -jsonl results into jq)"),
+		flagSet.BoolVarP(&options.PrettyJSON, "pretty-json", "pj", false, "indent the json results written to the output file (webhook payloads stay compact)"),
+		flagSet.BoolVarP(&options.NormalizeMatchedAt, "normalize-matched-at", "nma", false, "normalize matched-at urls (lowercase host, strip query, resolve path) in results"),
+		flagSet.StringSliceVarP(&options.IncludeFields, "include-fields", "ifl", nil, "top level fields to keep in the json results (comma-separated)", goflags.CommaSeparatedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.ExcludeFields, "exclude-fields", "efl", nil, "top level fields to remove from the json results (comma-separated)", goflags.CommaSeparatedStringSliceOptions),
+		flagSet.BoolVarP(&options.ExtractedValues, "extracted-values", "exv", false, "add the extracted results keyed by their extractor name to the results"),
+		flagSet.BoolVarP(&options.Fingerprint, "fingerprint", "fp", false, "add a fingerprint hash identifying the finding across scans to the results"),
+		flagSet.StringSliceVarP(&options.FingerprintFields, "fingerprint-fields", "fpf", nil, "result fields hashed into the fingerprint (template-id,host,matched-at,matcher-name)", goflags.CommaSeparatedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.SuppressTemplateIDs, "suppress-template-id", "stid", nil, "template ids (glob patterns) whose results are not written (comma-separated)", goflags.CommaSeparatedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.OnlyTemplateIDs, "only-template-id", "otid", nil, "template ids (glob patterns) whose results are only written (comma-separated)", goflags.CommaSeparatedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.AllowHosts, "allow-host", "ahst", nil, "hosts (cidr ranges or glob patterns) whose results are only written (comma-separated)", goflags.CommaSeparatedStringSliceOptions),
+		flagSet.StringSliceVarP(&options.DenyHosts, "deny-host", "dhst", nil, "hosts (cidr ranges or glob patterns) whose results are not written (comma-separated)", goflags.CommaSeparatedStringSliceOptions),
+		flagSet.BoolVarP(&options.IncludeTemplateSource, "include-template-source", "its", false, "include the yaml source of the matched template in the results"),
+		flagSet.BoolVarP(&options.ResolveIP, "resolve-ip", "rip", false, "resolve the ip address of the host of results missing it"),
+		flagSet.BoolVarP(&options.IncludeRawResponse, "include-raw-response", "irsp", false, "include the original response (base64) in the results along with the reconstructed one"),
+		flagSet.BoolVarP(&options.IncludeResponseBody, "include-response-body", "irb", false, "include the response body, decoded if chunked, in the reconstructed response of results"),
+		flagSet.BoolVarP(&options.ResponseStatusLine, "response-status-line", "rsl", false, "start the reconstructed response with a valid http status line (HTTP/1.1 200 OK)"),
+		flagSet.StringVarP(&options.ResponseLineEndings, "response-line-endings", "rle", "", "normalize the line endings of the stored and reconstructed responses (lf,crlf)"),
+		flagSet.BoolVarP(&options.DisableResponseReconstruction, "disable-response-reconstruction", "drr", false, "keep the original response in results instead of rebuilding it from its headers"),
+		flagSet.BoolVarP(&options.DisableFileResponseReconstruction, "disable-file-response-reconstruction", "dfrr", false, "keep the original response in the written results only, the webhook alerts get the reconstructed one"),
+		flagSet.BoolVarP(&options.DisableWebhookResponseReconstruction, "disable-webhook-response-reconstruction", "dwrr", false, "keep the original response in the webhook alerts only, the written results get the reconstructed one"),
+		flagSet.IntVarP(&options.OutputRotateSize, "output-rotate-size", "ors", 0, "rotate the output file to output.1, output.2, etc. once it grows past given bytes (0 disables it)"),
+		flagSet.DurationVarP(&options.OutputRotateInterval, "output-rotate-interval", "ori", 0, "rotate the output file once it is older than given duration (0 disables it)"),
+		flagSet.BoolVarP(&options.TolerateOutputErrors, "tolerate-output-errors", "toe", false, "log output file write errors (eg. disk full) once and keep scanning"),
+		flagSet.IntVarP(&options.TemplateSourceLimit, "template-source-limit", "tsl", 0, "maximum size in bytes of the included template source (0 for no limit)"),
+	)
+
+	flagSet.CreateGroup("webhook", "Webhook",
+		flagSet.BoolVarP(&options.WebhookErrors, "webhook-errors", "whe", false, "forward errors written to the error log as scan.error webhook events"),
+		flagSet.IntVarP(&options.WebhookRetries, "webhook-retries", "whr", 3, "number of times to retry a failed webhook delivery"),
+		flagSet.BoolVar(&options.DryRun, "dry-run", false, "log webhook and status api payloads instead of sending them"),
+		flagSet.IntVarP(&options.WebhookQueueSize, "webhook-queue-size", "whqs", 0, "size of the queue of alerts delivered asynchronously (0 delivers synchronously)"),
+		flagSet.IntVarP(&options.WebhookWorkers, "webhook-workers", "whw", 1, "number of workers delivering queued alerts"),
+		flagSet.StringVarP(&options.WebhookDropPolicy, "webhook-drop-policy", "whdp", "block", "policy when the alert queue is full (block,drop-oldest,drop-newest)"),
+		flagSet.DurationVarP(&options.WebhookDrainTimeout, "webhook-drain-timeout", "whdt", 30*time.Second, "maximum time to wait for queued alerts to be delivered on exit"),
+		flagSet.StringVarP(&options.WebhookSecret, "webhook-secret", "whs", "", "secret to sign webhook payloads with (HMAC-SHA256 in X-Signature header)"),
+		flagSet.StringVarP(&options.WebhookAuthHeader, "webhook-auth-header", "whah", "", "header to send the webhook token in on webhook and status requests (eg. Authorization)"),
+		flagSet.StringVarP(&options.WebhookAuthScheme, "webhook-auth-scheme", "whas", "Bearer", "scheme prefixing the webhook token in the auth header"),
+		flagSet.BoolVarP(&options.WebhookOmitBodyToken, "webhook-omit-body-token", "whot", false, "send the webhook token only in the auth header instead of the payload meta"),
+		flagSet.BoolVarP(&options.WebhookBatchByHost, "webhook-batch-by-host", "whbh", false, "send the findings of a host in a single alert.batch event once the scan moves to another host"),
+		flagSet.IntVarP(&options.WebhookBatchSize, "webhook-batch-size", "whbs", 0, "maximum number of findings of a host in a batch (0 for no limit)"),
+		flagSet.StringVarP(&options.MetaHostname, "webhook-hostname", "whhn", "", "hostname sent in the meta of webhook events (default machine hostname)"),
+		flagSet.VarP(&options.AlertExcludeSeverities, "alert-exclude-severity", "aes", fmt.Sprintf("result severities not sent to the webhook. Possible values: %s", severity.GetSupportedSeverities().String())),
+		flagSet.BoolVarP(&options.AlertExcludeOutput, "alert-exclude-output", "aeo", false, "drop the results of the excluded alert severities from the output as well"),
+		flagSet.IntVarP(&options.MaxAlerts, "max-alerts", "mxa", 0, "maximum number of alerts sent to the webhook for the scan (0 = no limit)"),
+		flagSet.BoolVarP(&options.MaxAlertsSkipOutput, "max-alerts-skip-output", "mxaso", false, "drop the results over the alert quota from the output as well"),
+		flagSet.StringVarP(&options.BackupWebhookURL, "backup-webhook-url", "bwh", "", "backup webhook url to deliver alerts to when the webhook fails (supports ${VAR} placeholders)"),
+		flagSet.StringVarP(&options.WebhookSpoolFile, "webhook-spool-file", "whsf", "", "file to spool undelivered alerts to, replayed on the next run"),
+		flagSet.StringVarP(&options.AlertFormat, "webhook-alert-format", "whaf", "json", "format of the webhook payloads (json,msgpack)"),
+		flagSet.StringVarP(&options.EnvelopeVersion, "webhook-envelope-version", "whev", "v1", "version of the envelope wrapping the webhook events (v1,v2)"),
+		flagSet.StringVarP(&options.AlertTransport, "alert-transport", "alt", "webhook", "transport the alerts are delivered with (webhook,pubsub)"),
+		flagSet.StringVarP(&options.PubSubTopic, "pubsub-topic", "whps", "", "google cloud pub/sub topic the alerts are published to with the pubsub transport"),
+		flagSet.StringVarP(&options.AttachmentStore, "webhook-attachment-store", "whast", "", "store the request/response of the alerts as attachments referenced by the alerts (file,http)"),
+		flagSet.StringVarP(&options.AttachmentURL, "webhook-attachment-url", "whatu", "", "base url the attachments are uploaded to with the http attachment store"),
+		flagSet.IntVarP(&options.WebhookGzipThreshold, "webhook-gzip-threshold", "whgz", 0, "gzip compress webhook payloads larger than given bytes (0 disables compression)"),
+		flagSet.StringVarP(&options.WebhookTemplate, "webhook-template", "wht", "", "go template file rendering the webhook alert payload (default astra format)"),
+		flagSet.IntVarP(&options.WebhookRateLimit, "webhook-rate-limit", "whrl", 0, "maximum number of webhook and status api requests to send per second (0 for no limit)"),
+		flagSet.IntVarP(&options.MaxAlertBytes, "webhook-max-alert-bytes", "whmb", 0, "maximum size of a webhook alert, truncating the request and response to fit (0 for no limit)"),
+		flagSet.StringVarP(&options.StatusMethod, "status-method", "stm", "PATCH", "http method of the scan status change request (PATCH,PUT,POST)"),
+		flagSet.StringVarP(&options.StatusPathTemplate, "status-path", "stp", "/api/nuclei/{scanId}", "path of the scan status change request ({scanId} and {serviceName} are replaced)"),
+		flagSet.StringVarP(&options.StatusScheme, "status-scheme", "sts", "http", "scheme of the status api service (http,https)"),
+		flagSet.StringVarP(&options.StatusCABundle, "status-ca-bundle", "stca", "", "pem file of the certificate authorities to trust for the status api service"),
+		flagSet.BoolVarP(&options.StatusInsecureSkipVerify, "status-insecure", "sti", false, "disable tls certificate verification for the status api service"),
+		flagSet.BoolVarP(&options.RequireStatusAPI, "status-required", "str", false, "fail to start the scan if the status api is unreachable instead of continuing without it"),
 	)
 
 	flagSet.CreateGroup("configs", "Configurations",
@@ -282,6 +359,7 @@ on extensive configurability, massive extensibility and ease of use.`)
 		flagSet.BoolVarP(&options.ProxyInternal, "proxy-internal", "pi", false, "proxy all internal requests"),
 		flagSet.BoolVarP(&options.ListDslSignatures, "list-dsl-function", "ldf", false, "list all supported DSL function signatures"),
 		flagSet.StringVarP(&options.TraceLogFile, "trace-log", "tlog", "", "file to write sent requests trace log"),
+		flagSet.StringVarP(&options.TraceLogFormat, "trace-log-format", "tlf", "jsonl", "format of the trace log (jsonl,csv)"),
 		flagSet.StringVarP(&options.ErrorLogFile, "error-log", "elog", "", "file to write sent requests error log"),
 		flagSet.BoolVar(&options.Version, "version", false, "show nuclei version"),
 		flagSet.BoolVarP(&options.HangMonitor, "hang-monitor", "hm", false, "enable nuclei hang monitoring"),
@@ -12,6 +12,16 @@ const (
 	fgOrange uint8 = 208
 )
 
+// Themes available to color the severities
+const (
+	// ThemeDefault colors the severities with the standard palette
+	ThemeDefault = "default"
+	// ThemeHighContrast colors the severities with bold, bright colors
+	ThemeHighContrast = "high-contrast"
+	// ThemeMonochrome doesn't color the severities
+	ThemeMonochrome = "monochrome"
+)
+
 func GetColor(colorizer aurora.Aurora, templateSeverity fmt.Stringer) string {
 	var method func(arg interface{}) aurora.Value
 	switch templateSeverity {
@@ -32,8 +42,46 @@ func GetColor(colorizer aurora.Aurora, templateSeverity fmt.Stringer) string {
 	return method(templateSeverity.String()).String()
 }
 
+// GetHighContrastColor returns the severity colored with the high contrast theme
+func GetHighContrastColor(colorizer aurora.Aurora, templateSeverity fmt.Stringer) string {
+	var value aurora.Value
+	switch templateSeverity {
+	case severity.Info:
+		value = colorizer.BrightCyan(templateSeverity.String())
+	case severity.Low:
+		value = colorizer.BrightGreen(templateSeverity.String())
+	case severity.Medium:
+		value = colorizer.BrightYellow(templateSeverity.String())
+	case severity.High:
+		value = colorizer.BrightMagenta(templateSeverity.String())
+	case severity.Critical:
+		value = colorizer.BgRed(colorizer.BrightWhite(templateSeverity.String()))
+	default:
+		value = colorizer.BrightWhite(templateSeverity.String())
+	}
+	return colorizer.Bold(value).String()
+}
+
 func New(colorizer aurora.Aurora) func(severity.Severity) string {
 	return func(severity severity.Severity) string {
 		return GetColor(colorizer, severity)
 	}
 }
+
+// NewTheme returns the function coloring the severities with the named theme.
+// An empty theme is the default theme.
+func NewTheme(colorizer aurora.Aurora, theme string) (func(severity.Severity) string, error) {
+	switch theme {
+	case "", ThemeDefault:
+		return New(colorizer), nil
+	case ThemeHighContrast:
+		return func(severity severity.Severity) string {
+			return GetHighContrastColor(colorizer, severity)
+		}, nil
+	case ThemeMonochrome:
+		return func(severity severity.Severity) string {
+			return severity.String()
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid color theme %q: expected one of %s, %s, %s", theme, ThemeDefault, ThemeHighContrast, ThemeMonochrome)
+}
@@ -0,0 +1,64 @@
+package colorizer
+
+import (
+	"testing"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+func TestNewTheme(t *testing.T) {
+	colorizer := aurora.NewAurora(true)
+
+	tests := []struct {
+		theme    string
+		expected map[severity.Severity]string
+	}{
+		{ThemeDefault, map[severity.Severity]string{
+			severity.Info:     "\x1b[34minfo\x1b[0m",
+			severity.Low:      "\x1b[32mlow\x1b[0m",
+			severity.Medium:   "\x1b[33mmedium\x1b[0m",
+			severity.High:     "\x1b[38;5;208mhigh\x1b[0m",
+			severity.Critical: "\x1b[31mcritical\x1b[0m",
+			severity.Unknown:  "\x1b[37munknown\x1b[0m",
+		}},
+		{ThemeHighContrast, map[severity.Severity]string{
+			severity.Info:     "\x1b[1;96minfo\x1b[0m",
+			severity.Low:      "\x1b[1;92mlow\x1b[0m",
+			severity.Medium:   "\x1b[1;93mmedium\x1b[0m",
+			severity.High:     "\x1b[1;95mhigh\x1b[0m",
+			severity.Critical: "\x1b[1;97;41mcritical\x1b[0m",
+			severity.Unknown:  "\x1b[1;97munknown\x1b[0m",
+		}},
+		{ThemeMonochrome, map[severity.Severity]string{
+			severity.Info:     "info",
+			severity.Low:      "low",
+			severity.Medium:   "medium",
+			severity.High:     "high",
+			severity.Critical: "critical",
+			severity.Unknown:  "unknown",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.theme, func(t *testing.T) {
+			severityColors, err := NewTheme(colorizer, test.theme)
+			require.NoError(t, err)
+			for templateSeverity, expected := range test.expected {
+				require.Equal(t, expected, severityColors(templateSeverity), "unexpected styling for %s", templateSeverity)
+			}
+		})
+	}
+
+	t.Run("NoColor", func(t *testing.T) {
+		severityColors, err := NewTheme(aurora.NewAurora(false), ThemeHighContrast)
+		require.NoError(t, err)
+		require.Equal(t, "critical", severityColors(severity.Critical))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := NewTheme(colorizer, "solarized")
+		require.ErrorContains(t, err, `invalid color theme "solarized"`)
+	})
+}
@@ -166,7 +166,15 @@ func New(options *types.Options) (*Runner, error) {
 	useColor := !options.NoColor
 	runner.colorizer = aurora.NewAurora(useColor)
 	templates.Colorizer = runner.colorizer
-	templates.SeverityColorizer = colorizer.New(runner.colorizer)
+	theme := options.ColorTheme
+	if options.NoColor {
+		theme = colorizer.ThemeMonochrome
+	}
+	severityColors, err := colorizer.NewTheme(runner.colorizer, theme)
+	if err != nil {
+		return nil, err
+	}
+	templates.SeverityColorizer = severityColors
 
 	if options.EnablePprof {
 		server := &http.Server{
@@ -502,6 +510,13 @@ func (r *Runner) RunEnumeration() error {
 		executerOpts.InputHelper.InputsHTTP = inputHelpers
 	}
 
+	// the scan is started whatever the execution path, once its targets
+	// and templates are known, instead of on the first result
+	if writer, ok := r.output.(*output.StandardWriter); ok {
+		writer.SetScanInfo(int(r.hmapInputProvider.Count()), len(store.Templates())+len(store.Workflows()))
+		writer.Start()
+	}
+
 	enumeration := false
 	var results *atomic.Bool
 	if r.options.Cloud {
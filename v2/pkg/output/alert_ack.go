@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxAlertAckSize is the maximum size of the webhook response body read as an acknowledgement
+const maxAlertAckSize = 64 * 1024
+
+// maxAlertAckRetries is the number of times an alert is delivered again when
+// the webhook asks for it before it is spooled.
+const maxAlertAckRetries = 3
+
+// maxAlertRetryAfter caps the delay the webhook can ask to retry an alert after
+const maxAlertRetryAfter = 5 * time.Minute
+
+// alertAck is the acknowledgement of an alert the webhook can return in
+// its response body. All the fields are optional.
+type alertAck struct {
+	// Accepted is false if the webhook rejected the alert
+	Accepted *bool `json:"accepted"`
+	// RetryAfter is the number of seconds to deliver the alert again after
+	RetryAfter int `json:"retryAfter"`
+	// ID is the id assigned to the alert by the webhook
+	ID string `json:"id"`
+}
+
+// readAlertAck reads the acknowledgement in the response body and closes
+// it. It returns an empty acknowledgement if the body is empty, and an
+// error along with it if the body isn't an acknowledgement.
+func readAlertAck(resp *http.Response) (*alertAck, error) {
+	defer resp.Body.Close()
+
+	ack := &alertAck{}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAlertAckSize))
+	if err != nil {
+		return ack, errors.Wrap(err, "could not read webhook acknowledgement")
+	}
+	if data = bytes.TrimSpace(data); len(data) == 0 {
+		return ack, nil
+	}
+	if err := json.Unmarshal(data, ack); err != nil {
+		return &alertAck{}, errors.Wrap(err, "could not parse webhook acknowledgement")
+	}
+	return ack, nil
+}
+
+// rejected returns true if the webhook rejected the alert for good
+func (a *alertAck) rejected() bool {
+	return a.Accepted != nil && !*a.Accepted && a.RetryAfter <= 0
+}
+
+// retryDelay returns the delay to deliver the alert again after, 0 if the
+// webhook didn't ask for it. The retry after is counted in unit.
+func (a *alertAck) retryDelay(unit time.Duration) time.Duration {
+	if a.RetryAfter <= 0 {
+		return 0
+	}
+	if unit == 0 {
+		unit = time.Second
+	}
+	if time.Duration(a.RetryAfter) > maxAlertRetryAfter/unit {
+		return maxAlertRetryAfter
+	}
+	return time.Duration(a.RetryAfter) * unit
+}
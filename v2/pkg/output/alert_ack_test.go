@@ -0,0 +1,90 @@
+package output
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookAcknowledgement(t *testing.T) {
+	newAckWriter := func(t *testing.T, bodies ...string) (*StandardWriter, *atomic.Int32) {
+		var hits atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			hit := int(hits.Add(1)) - 1
+			if hit >= len(bodies) {
+				hit = len(bodies) - 1
+			}
+			_, _ = rw.Write([]byte(bodies[hit]))
+		}))
+		t.Cleanup(ts.Close)
+
+		w := newTestWriter(ts.URL)
+		w.verbose = true
+		w.retryAfterUnit = time.Millisecond
+		return w, &hits
+	}
+
+	t.Run("Accepted", func(t *testing.T) {
+		logs := captureLogs(t)
+		w, hits := newAckWriter(t, `{"accepted":true,"id":"alert-42"}`)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.Equal(t, int32(1), hits.Load())
+		require.Equal(t, uint64(1), w.Metrics().AlertsSent)
+		require.Contains(t, logs.String(), "accepted by webhook with id alert-42")
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		w, hits := newAckWriter(t, `{"accepted":false,"retryAfter":5}`, `{"retryAfter":5}`, `{"accepted":true}`)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.Equal(t, int32(3), hits.Load(), "alert wasn't delivered again")
+		require.Equal(t, uint64(1), w.Metrics().AlertsSent)
+	})
+
+	t.Run("RetryAfterExhausted", func(t *testing.T) {
+		w, hits := newAckWriter(t, `{"retryAfter":1}`)
+		spoolFile := filepath.Join(t.TempDir(), "spool.jsonl")
+		w.spool = newAlertSpool(spoolFile)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.Equal(t, int32(maxAlertAckRetries+1), hits.Load())
+		require.Equal(t, uint64(0), w.Metrics().AlertsSent)
+
+		data, err := os.ReadFile(spoolFile)
+		require.NoError(t, err)
+		require.Equal(t, 1, strings.Count(string(data), "\n"), "alert wasn't spooled")
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		logs := captureLogs(t)
+		w, hits := newAckWriter(t, `{"accepted":false}`)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", TemplateURL: "https://templates.example.com/git-config"}))
+		require.Equal(t, int32(1), hits.Load())
+		require.Equal(t, uint64(0), w.Metrics().AlertsSent)
+		require.Contains(t, logs.String(), "Webhook rejected alert for https://templates.example.com/git-config")
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, body := range []string{"ok", `{"retryAfter":"soon"}`, ""} {
+			logs := captureLogs(t)
+			w, hits := newAckWriter(t, body)
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+			require.Equal(t, int32(1), hits.Load())
+			require.Equal(t, uint64(1), w.Metrics().AlertsSent, "alert with %q acknowledgement wasn't counted", body)
+			if body != "" {
+				require.Contains(t, logs.String(), "Ignoring webhook acknowledgement")
+			}
+		}
+	})
+}
+
+func TestAlertAckRetryDelay(t *testing.T) {
+	require.Equal(t, time.Duration(0), (&alertAck{}).retryDelay(0))
+	require.Equal(t, 3*time.Second, (&alertAck{RetryAfter: 3}).retryDelay(0))
+	require.Equal(t, maxAlertRetryAfter, (&alertAck{RetryAfter: 1 << 40}).retryDelay(0))
+}
@@ -0,0 +1,124 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// alertBatchEvent is the webhook event of a batch of findings of a host
+const alertBatchEvent = "alert.batch"
+
+// alertBatchContext is the context of a batch of findings of a host
+type alertBatchContext struct {
+	Host     string            `json:"host"`
+	Count    int               `json:"count"`
+	Findings []json.RawMessage `json:"findings"`
+}
+
+// alertBatch is the pending findings of a host
+type alertBatch struct {
+	findings []*alert
+}
+
+// alertBatcher groups the alerts by host, sending a single alert with
+// the findings of a host once findings of another host are written, the
+// batch reaches its size or the batches are flushed.
+type alertBatcher struct {
+	mu       sync.Mutex
+	size     int
+	lastHost string
+	batches  map[string]*alertBatch
+	send     func(*alert)
+}
+
+// newAlertBatcher returns a batcher sending the batches with send. A
+// batch is sent once it has size findings, 0 for no limit.
+func newAlertBatcher(size int, send func(*alert)) *alertBatcher {
+	return &alertBatcher{size: size, batches: make(map[string]*alertBatch), send: send}
+}
+
+// add adds the alert to the batch of the host
+func (b *alertBatcher) add(host string, finding *alert) {
+	var ready []*alert
+
+	b.mu.Lock()
+	if b.lastHost != host {
+		if previous := b.take(b.lastHost); previous != nil {
+			ready = append(ready, previous)
+		}
+		b.lastHost = host
+	}
+	batch, ok := b.batches[host]
+	if !ok {
+		batch = &alertBatch{}
+		b.batches[host] = batch
+	}
+	batch.findings = append(batch.findings, finding)
+	if b.size > 0 && len(batch.findings) >= b.size {
+		ready = append(ready, b.take(host))
+	}
+	b.mu.Unlock()
+
+	// the alerts are sent outside the lock so a slow webhook
+	// doesn't block the batching of other hosts
+	for _, alert := range ready {
+		b.send(alert)
+	}
+}
+
+// flush sends the pending batches of all the hosts
+func (b *alertBatcher) flush() {
+	b.mu.Lock()
+	ready := make([]*alert, 0, len(b.batches))
+	for host := range b.batches {
+		if alert := b.take(host); alert != nil {
+			ready = append(ready, alert)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, alert := range ready {
+		b.send(alert)
+	}
+}
+
+// take removes the batch of the host returning it as an alert, nil if
+// the host has no pending findings. The mutex must be held.
+func (b *alertBatcher) take(host string) *alert {
+	batch, ok := b.batches[host]
+	if !ok {
+		return nil
+	}
+	delete(b.batches, host)
+
+	data, err := batchContext(host, batch.findings)
+	if err != nil {
+		gologger.Warning().Msgf("Could not marshal batch of findings of %s: %s\n", host, err)
+		return nil
+	}
+	keys := make([]string, 0, len(batch.findings))
+	for _, finding := range batch.findings {
+		keys = append(keys, finding.idempotencyKey)
+	}
+	return &alert{
+		templateURL:    fmt.Sprintf("%d findings of %s", len(batch.findings), host),
+		event:          alertBatchEvent,
+		context:        data,
+		idempotencyKey: rawIdempotencyKey([]byte(strings.Join(keys, ""))),
+		host:           host,
+		findings:       batch.findings,
+	}
+}
+
+// batchContext returns the context of the batch of findings of the host
+func batchContext(host string, findings []*alert) (json.RawMessage, error) {
+	contexts := make([]json.RawMessage, 0, len(findings))
+	for _, finding := range findings {
+		contexts = append(contexts, finding.context)
+	}
+	return jsonEncoder.Marshal(alertBatchContext{Host: host, Count: len(findings), Findings: contexts})
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardWriterBatchByHost(t *testing.T) {
+	type batch struct {
+		event   string
+		context alertBatchContext
+	}
+	var mu sync.Mutex
+	var batches []batch
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Meta    AstraMeta         `json:"meta"`
+			Context alertBatchContext `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		batches = append(batches, batch{event: request.Meta.Event, context: request.Context})
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	findingIDs := func(context alertBatchContext) []string {
+		var ids []string
+		for _, finding := range context.Findings {
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(finding, &fields))
+			ids = append(ids, fields["template-id"].(string))
+		}
+		return ids
+	}
+
+	t.Run("HostChange", func(t *testing.T) {
+		batches = nil
+		w := newTestWriter(ts.URL)
+		w.alertBatcher = newAlertBatcher(0, w.dispatchAlert)
+
+		for _, templateID := range []string{"first", "second", "third"} {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: templateID, Host: "https://a.example.com", Matched: "https://a.example.com/" + templateID}))
+		}
+		require.Empty(t, batches, "findings were sent before the host changed")
+
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "fourth", Host: "https://b.example.com"}))
+		require.Len(t, batches, 1)
+		require.Equal(t, alertBatchEvent, batches[0].event)
+		require.Equal(t, "https://a.example.com", batches[0].context.Host)
+		require.Equal(t, 3, batches[0].context.Count)
+		require.Equal(t, []string{"first", "second", "third"}, findingIDs(batches[0].context))
+
+		var finding map[string]interface{}
+		require.NoError(t, json.Unmarshal(batches[0].context.Findings[1], &finding))
+		require.Equal(t, "https://a.example.com/second", finding["matched-at"], "finding details were not preserved")
+
+		require.NoError(t, w.Flush())
+		require.Len(t, batches, 2)
+		require.Equal(t, "https://b.example.com", batches[1].context.Host)
+		require.Equal(t, []string{"fourth"}, findingIDs(batches[1].context))
+
+		require.NoError(t, w.Flush())
+		require.Len(t, batches, 2, "empty batches should not be sent")
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		batches = nil
+		w := newTestWriter(ts.URL)
+		w.alertBatcher = newAlertBatcher(2, w.dispatchAlert)
+
+		for _, templateID := range []string{"first", "second", "third"} {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: templateID, Host: "https://a.example.com"}))
+		}
+		require.Len(t, batches, 1)
+		require.Equal(t, []string{"first", "second"}, findingIDs(batches[0].context))
+
+		w.alertBatcher.flush()
+		require.Len(t, batches, 2)
+		require.Equal(t, []string{"third"}, findingIDs(batches[1].context))
+	})
+}
@@ -0,0 +1,230 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Policies applied when the alert queue is full
+const (
+	// DropPolicyBlock blocks the writer until there is space in the queue
+	DropPolicyBlock = "block"
+	// DropPolicyDropOldest drops the oldest queued alert to make space for the new one
+	DropPolicyDropOldest = "drop-oldest"
+	// DropPolicyDropNewest drops the new alert
+	DropPolicyDropNewest = "drop-newest"
+)
+
+// validateDropPolicy validates the alert queue drop policy
+func validateDropPolicy(policy string) error {
+	switch policy {
+	case DropPolicyBlock, DropPolicyDropOldest, DropPolicyDropNewest:
+		return nil
+	}
+	return fmt.Errorf("invalid alert queue drop policy %q: expected one of %s, %s, %s", policy, DropPolicyBlock, DropPolicyDropOldest, DropPolicyDropNewest)
+}
+
+// alert is a formatted result waiting for delivery to the webhook
+type alert struct {
+	templateURL    string
+	context        json.RawMessage
+	idempotencyKey string
+	// body is the payload rendered from the webhook template if any
+	body []byte
+	// event is the webhook event of the alert, alert when empty
+	event string
+	// webhookURL is the webhook the alert is routed to, the default one when empty
+	webhookURL string
+	// attachments is the event of the alert whose request and response are
+	// stored as attachments on delivery, see resolveAttachments
+	attachments *ResultEvent
+	// host and findings are the host and the alerts of a batch of findings
+	host     string
+	findings []*alert
+}
+
+// eventName returns the webhook event of the alert
+func (a *alert) eventName() string {
+	if a.event == "" {
+		return "alert"
+	}
+	return a.event
+}
+
+// alertQueue is a bounded queue of alerts delivered asynchronously
+// by a pool of workers.
+type alertQueue struct {
+	alerts  chan *alert
+	policy  string
+	deliver func(*alert)
+	dropped uint64
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+	// done is closed along with the queue to release the writers
+	// blocked on a full queue, tracked by senders.
+	done    chan struct{}
+	senders sync.WaitGroup
+
+	// pending is the number of queued or in-flight alerts, idle
+	// is closed once it drops to zero to wake up flush callers.
+	pendingMu sync.Mutex
+	pending   int
+	idle      chan struct{}
+}
+
+// newAlertQueue creates a new alert queue holding up to size alerts
+// delivered by the given number of workers.
+func newAlertQueue(size, workers int, policy string, deliver func(*alert)) *alertQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	queue := &alertQueue{
+		alerts:  make(chan *alert, size),
+		policy:  policy,
+		deliver: deliver,
+		done:    make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		queue.wg.Add(1)
+		go queue.worker()
+	}
+	return queue
+}
+
+func (q *alertQueue) worker() {
+	defer q.wg.Done()
+
+	for alert := range q.alerts {
+		q.deliver(alert)
+		q.addPending(-1)
+	}
+}
+
+// addPending updates the number of pending alerts
+func (q *alertQueue) addPending(delta int) {
+	q.pendingMu.Lock()
+	defer q.pendingMu.Unlock()
+
+	q.pending += delta
+	if q.pending == 0 && q.idle != nil {
+		close(q.idle)
+		q.idle = nil
+	}
+}
+
+// push adds an alert to the queue applying the drop policy if the queue is full.
+func (q *alertQueue) push(alert *alert) {
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		atomic.AddUint64(&q.dropped, 1)
+		return
+	}
+	q.addPending(1)
+
+	// the blocking send happens outside of the lock, so closing the
+	// queue isn't held up by the writers waiting on a full queue
+	if q.policy != DropPolicyDropNewest && q.policy != DropPolicyDropOldest {
+		q.senders.Add(1)
+		q.mu.RUnlock()
+		defer q.senders.Done()
+
+		select {
+		case q.alerts <- alert:
+		case <-q.done:
+			atomic.AddUint64(&q.dropped, 1)
+			q.addPending(-1)
+		}
+		return
+	}
+	defer q.mu.RUnlock()
+
+	switch q.policy {
+	case DropPolicyDropNewest:
+		select {
+		case q.alerts <- alert:
+		default:
+			atomic.AddUint64(&q.dropped, 1)
+			q.addPending(-1)
+		}
+	case DropPolicyDropOldest:
+		for {
+			select {
+			case q.alerts <- alert:
+				return
+			default:
+			}
+			select {
+			case <-q.alerts:
+				atomic.AddUint64(&q.dropped, 1)
+				q.addPending(-1)
+			default:
+			}
+		}
+	}
+}
+
+// flush waits for the queued and in-flight alerts to be delivered
+// while still accepting new ones. It returns false if the timeout
+// expired before the queue was empty.
+func (q *alertQueue) flush(timeout time.Duration) bool {
+	q.pendingMu.Lock()
+	if q.pending == 0 {
+		q.pendingMu.Unlock()
+		return true
+	}
+	if q.idle == nil {
+		q.idle = make(chan struct{})
+	}
+	idle := q.idle
+	q.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// close stops accepting new alerts and waits for the queued ones
+// to be delivered. It returns false if the timeout expired before
+// the queue was drained.
+func (q *alertQueue) close(timeout time.Duration) bool {
+	expired := time.After(timeout)
+
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.done)
+		go func() {
+			// the alerts channel is closed once the blocked writers gave up
+			q.senders.Wait()
+			close(q.alerts)
+		}()
+	}
+	q.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return true
+	case <-expired:
+		return false
+	}
+}
+
+// Dropped returns the number of alerts dropped by the queue
+func (q *alertQueue) Dropped() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
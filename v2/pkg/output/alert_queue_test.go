@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestAlertQueueDropPolicy(t *testing.T) {
+	tests := []struct {
+		policy    string
+		delivered []string
+	}{
+		{policy: DropPolicyDropNewest, delivered: []string{"1", "2", "3"}},
+		{policy: DropPolicyDropOldest, delivered: []string{"1", "4", "5"}},
+	}
+	for _, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			var mu sync.Mutex
+			var delivered []string
+			started := make(chan struct{}, 1)
+			release := make(chan struct{})
+			queue := newAlertQueue(2, 1, test.policy, func(a *alert) {
+				select {
+				case started <- struct{}{}:
+				default:
+				}
+				<-release
+				mu.Lock()
+				delivered = append(delivered, a.idempotencyKey)
+				mu.Unlock()
+			})
+
+			// the first alert is picked up by the worker, blocking it
+			queue.push(&alert{idempotencyKey: "1"})
+			<-started
+			for i := 2; i <= 5; i++ {
+				queue.push(&alert{idempotencyKey: fmt.Sprint(i)})
+			}
+			require.Equal(t, uint64(2), queue.Dropped())
+
+			close(release)
+			require.True(t, queue.close(time.Second))
+			require.Equal(t, test.delivered, delivered)
+		})
+	}
+}
+
+func TestAlertQueueClose(t *testing.T) {
+	t.Run("Drain", func(t *testing.T) {
+		var mu sync.Mutex
+		var delivered int
+		queue := newAlertQueue(10, 2, DropPolicyBlock, func(a *alert) {
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			delivered++
+			mu.Unlock()
+		})
+		for i := 0; i < 10; i++ {
+			queue.push(&alert{})
+		}
+		require.True(t, queue.close(time.Second), "queue was not drained")
+		require.Equal(t, 10, delivered)
+		require.Equal(t, uint64(0), queue.Dropped())
+
+		queue.push(&alert{})
+		require.Equal(t, uint64(1), queue.Dropped(), "alert pushed to a closed queue was not dropped")
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		queue := newAlertQueue(10, 1, DropPolicyBlock, func(a *alert) { <-release })
+		queue.push(&alert{})
+		require.False(t, queue.close(50*time.Millisecond))
+	})
+
+	t.Run("HungWebhook", func(t *testing.T) {
+		release := make(chan struct{})
+		var releaseOnce sync.Once
+		releaseWebhook := func() { releaseOnce.Do(func() { close(release) }) }
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) { <-release }))
+		defer ts.Close()
+		defer releaseWebhook()
+
+		w := newTestWriter(ts.URL)
+		w.alertQueue = newAlertQueue(1, 1, DropPolicyBlock, w.deliverAlert)
+		written := make(chan struct{})
+		go func() {
+			defer close(written)
+			// the first alert hangs in the worker, the second fills the
+			// queue and the third blocks the writer
+			for i := 0; i < 3; i++ {
+				_ = w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)})
+			}
+		}()
+		require.Eventually(t, func() bool {
+			w.alertQueue.pendingMu.Lock()
+			defer w.alertQueue.pendingMu.Unlock()
+			return w.alertQueue.pending == 3
+		}, 5*time.Second, 10*time.Millisecond)
+
+		started := time.Now()
+		require.False(t, w.alertQueue.close(100*time.Millisecond))
+		require.Less(t, time.Since(started), time.Second, "close didn't honor the drain timeout")
+		select {
+		case <-written:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the blocked writer wasn't released by close")
+		}
+		require.Equal(t, uint64(1), w.alertQueue.Dropped())
+
+		// the queued alert is delivered once the webhook is back
+		releaseWebhook()
+		require.True(t, w.alertQueue.close(5*time.Second))
+	})
+}
+
+func TestStandardWriterAlertQueue(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			return
+		}
+		var body AstraAlertRequest
+		_ = jsonEncoder.NewDecoder(r.Body).Decode(&body)
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		events = append(events, body.Meta.Event)
+		mu.Unlock()
+	}))
+
+	w, err := NewStandardWriter(&types.Options{JSONL: true, WebhookQueueSize: 10, WebhookWorkers: 2, WebhookDropPolicy: DropPolicyBlock, WebhookDrainTimeout: 5 * time.Second})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)}))
+	}
+	w.Close()
+
+	require.Equal(t, []string{"scan.started", "alert", "alert", "alert", "alert", "alert", "scan.summary", "scan.complete"}, events, "queued alerts were not delivered before completion")
+
+	t.Run("InvalidDropPolicy", func(t *testing.T) {
+		_, err := NewStandardWriter(&types.Options{WebhookQueueSize: 10, WebhookDropPolicy: "drop-all"})
+		require.ErrorContains(t, err, "invalid alert queue drop policy")
+	})
+}
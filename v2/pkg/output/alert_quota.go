@@ -0,0 +1,50 @@
+package output
+
+import (
+	"context"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// quotaExceededEvent is the webhook event sent once the alert quota of the scan is reached
+const quotaExceededEvent = "quota.exceeded"
+
+// quotaContext is the context of the quota exceeded event
+type quotaContext struct {
+	Reason    string `json:"reason"`
+	MaxAlerts int64  `json:"max-alerts"`
+}
+
+// reserveAlert reserves an alert of the quota of the scan, returning false
+// once the quota is reached. The quota exceeded event is sent the first
+// time an alert is over the quota, in the background so the concurrent
+// writes aren't held by its delivery. Close waits for it.
+func (w *StandardWriter) reserveAlert() bool {
+	if w.maxAlerts <= 0 || w.alertCount.Add(1) <= w.maxAlerts {
+		return true
+	}
+	w.metrics.alertsOverQuota.Add(1)
+	w.quotaOnce.Do(func() {
+		gologger.Warning().Msgf("Alert quota of %d reached, no more alerts are sent for this scan\n", w.maxAlerts)
+
+		w.quotaEvent.Add(1)
+		go func() {
+			defer w.quotaEvent.Done()
+			w.sendQuotaExceeded()
+		}()
+	})
+	return false
+}
+
+// sendQuotaExceeded triggers the `quota.exceeded` event on the webhook
+func (w *StandardWriter) sendQuotaExceeded() {
+	data, _ := jsonEncoder.Marshal(quotaContext{Reason: "Alert quota exceeded", MaxAlerts: w.maxAlerts})
+	resp, err := w.sendAstraEvent(context.Background(), quotaExceededEvent, data, "")
+	if err != nil {
+		gologger.Warning().Msgf("Could not send quota exceeded event: %s\n", err)
+		return
+	}
+	resp.Body.Close()
+
+	w.logVerbose("Request status received -> %s for quota exceeded\n", resp.Status)
+}
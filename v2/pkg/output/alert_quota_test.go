@@ -0,0 +1,150 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardWriterAlertQuota(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	var quota json.RawMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Meta    AstraMeta       `json:"meta"`
+			Context json.RawMessage `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, body.Meta.Event)
+		if body.Meta.Event == quotaExceededEvent {
+			quota = body.Context
+		}
+	}))
+	defer ts.Close()
+
+	newQuotaWriter := func(skipOutput bool) (*StandardWriter, *testWriteCloser) {
+		events, quota = nil, nil
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.outputFile = outputFile
+		w.maxAlerts = 3
+		w.quotaSkipOutput = skipOutput
+		return w, outputFile
+	}
+
+	w, outputFile := newQuotaWriter(false)
+	logs := captureLogs(t)
+	for i := 0; i < 6; i++ {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)}))
+	}
+	w.quotaEvent.Wait()
+	require.Equal(t, []string{"alert", "alert", "alert", quotaExceededEvent}, events, "alerts were sent over the quota")
+	require.JSONEq(t, `{"reason":"Alert quota exceeded","max-alerts":3}`, string(quota))
+	require.Equal(t, uint64(3), w.Metrics().AlertsSent)
+	require.Equal(t, uint64(3), w.Metrics().AlertsOverQuota)
+	require.Equal(t, 1, strings.Count(logs.String(), "Alert quota of 3 reached"))
+	require.Equal(t, 6, strings.Count(outputFile.String(), `"template-id"`), "results over the quota weren't written to the output")
+
+	t.Run("SkipOutput", func(t *testing.T) {
+		w, outputFile := newQuotaWriter(true)
+		for i := 0; i < 5; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)}))
+		}
+		w.quotaEvent.Wait()
+		require.Equal(t, []string{"alert", "alert", "alert", quotaExceededEvent}, events)
+		require.Equal(t, 3, strings.Count(outputFile.String(), `"template-id"`), "results over the quota were written to the output")
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		w, _ := newQuotaWriter(false)
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_ = w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)})
+			}(i)
+		}
+		wg.Wait()
+		w.quotaEvent.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		var alerts, exceeded int
+		for _, event := range events {
+			switch event {
+			case "alert":
+				alerts++
+			case quotaExceededEvent:
+				exceeded++
+			}
+		}
+		require.Equal(t, 3, alerts)
+		require.Equal(t, 1, exceeded, "the quota exceeded event wasn't sent once")
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		w, _ := newQuotaWriter(false)
+		w.matcherStatus = true
+		for i := 0; i < 5; i++ {
+			require.NoError(t, w.WriteFailure(InternalEvent{"template-id": fmt.Sprintf("template-%d", i)}))
+		}
+		for i := 0; i < 3; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)}))
+		}
+		w.quotaEvent.Wait()
+		require.Equal(t, []string{noMatchEvent, noMatchEvent, noMatchEvent, noMatchEvent, noMatchEvent, "alert", "alert", "alert"}, events, "the failures used up the quota")
+		require.Equal(t, uint64(0), w.Metrics().AlertsOverQuota)
+	})
+
+	t.Run("NoLimit", func(t *testing.T) {
+		w, _ := newQuotaWriter(false)
+		w.maxAlerts = 0
+		for i := 0; i < 5; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		}
+		require.Len(t, events, 5)
+		require.Equal(t, uint64(0), w.Metrics().AlertsOverQuota)
+	})
+}
+
+func TestStandardWriterAlertQuotaAsync(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Meta AstraMeta `json:"meta"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Meta.Event == quotaExceededEvent {
+			<-release
+		}
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	w.maxAlerts = 1
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "template-0"}))
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		_ = w.Write(&ResultEvent{TemplateID: "template-1"})
+		_ = w.Write(&ResultEvent{TemplateID: "template-2"})
+	}()
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the writes were held by the quota exceeded event")
+	}
+	close(release)
+	w.quotaEvent.Wait()
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"strings"
+
+	b64 "encoding/base64"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+)
+
+// fitAlertContext returns the json context of the alert for the event,
+// truncating the longest of the request, response and other raw fields
+// until the astra payload fits in the maximum alert size.
+//
+// The event is copied, the truncated fields are only sent to the webhook.
+func (w *StandardWriter) fitAlertContext(event *ResultEvent, data []byte, alertEvent, idempotencyKey string) ([]byte, error) {
+	overhead, err := w.alertOverhead(alertEvent, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	budget := w.maxAlertBytes - overhead
+	if len(data) <= budget {
+		return data, nil
+	}
+
+	truncated := *event
+	truncated.Truncated = true
+	if event.Interaction != nil {
+		interaction := *event.Interaction
+		truncated.Interaction = &interaction
+	}
+	fields := []*string{&truncated.Request, &truncated.Response, &truncated.RawResponse, &truncated.CURLCommand, &truncated.TemplateSource}
+	// the proofs are base64 encoded by now, they are cut decoded
+	encoded := map[*string]struct{}{&truncated.Request: {}, &truncated.Response: {}, &truncated.RawResponse: {}}
+	if truncated.Interaction != nil {
+		fields = append(fields, &truncated.Interaction.RawRequest, &truncated.Interaction.RawResponse)
+		encoded[&truncated.Interaction.RawRequest] = struct{}{}
+		encoded[&truncated.Interaction.RawResponse] = struct{}{}
+	}
+
+	for {
+		if data, err = w.formatJSON(&truncated); err != nil {
+			return nil, errors.Wrap(err, "could not format truncated alert")
+		}
+		if len(data) <= budget {
+			return data, nil
+		}
+		longest := longestField(fields)
+		if longest == nil {
+			gologger.Warning().Msgf("Alert for %s is %d bytes after truncation, above the %d bytes limit\n", event.TemplateID, len(data)+overhead, w.maxAlertBytes)
+			return data, nil
+		}
+		// escaping makes the encoded field longer than its value, cut at
+		// least the excess and retry until the payload fits.
+		size := len(*longest) - (len(data) - budget)
+		if size < 0 {
+			size = 0
+		}
+		if _, ok := encoded[longest]; ok {
+			*longest = truncateEncoded(*longest, size)
+		} else {
+			*longest = strings.ToValidUTF8((*longest)[:size], "")
+		}
+	}
+}
+
+// truncateEncoded truncates the base64 encoded value so its encoding is at
+// most size bytes. The value is cut decoded and encoded again, cutting the
+// encoding itself would leave it undecodable.
+func truncateEncoded(value string, size int) string {
+	decoded, err := b64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return ""
+	}
+	if limit := size / 4 * 3; len(decoded) > limit {
+		decoded = decoded[:limit]
+	}
+	return encodeBase64(string(decoded))
+}
+
+// alertOverhead returns the size of the astra payload without the context
+func (w *StandardWriter) alertOverhead(alertEvent, idempotencyKey string) (int, error) {
+	payload, err := w.astraRequestBody(alertEvent, []byte("{}"), idempotencyKey)
+	if err != nil {
+		return 0, err
+	}
+	return len(payload) - len("{}"), nil
+}
+
+// longestField returns the longest non empty field if any
+func longestField(fields []*string) *string {
+	var longest *string
+	for _, field := range fields {
+		if len(*field) > 0 && (longest == nil || len(*field) > len(*longest)) {
+			longest = field
+		}
+	}
+	return longest
+}
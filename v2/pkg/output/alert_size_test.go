@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardWriterMaxAlertBytes(t *testing.T) {
+	var received []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	const maxAlertBytes = 4096
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.jsonReqResp = true
+	w.outputFile = outputFile
+	w.maxAlertBytes = maxAlertBytes
+	w.AstraMeta = AstraMeta{AuditId: "audit-id", ScanId: "scan-id", WebhookToken: "webhook-token"}
+
+	t.Run("Oversized", func(t *testing.T) {
+		rawRequest := "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n" + strings.Repeat("A", 20000)
+		event := &ResultEvent{
+			TemplateID:  "test",
+			Host:        "https://example.com",
+			Request:     rawRequest,
+			CURLCommand: "curl -X GET https://example.com -d '" + strings.Repeat("\"<>", 100) + "'",
+		}
+		require.NoError(t, w.Write(event))
+		require.LessOrEqual(t, len(received), maxAlertBytes)
+
+		var request struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		require.NoError(t, json.Unmarshal(received, &request))
+		require.Equal(t, true, request.Context["truncated"])
+		require.Equal(t, "test", request.Context["template-id"])
+		require.Less(t, len(request.Context["request"].(string)), len(event.Request))
+		decoded, err := base64.StdEncoding.DecodeString(request.Context["request"].(string))
+		require.NoError(t, err, "the truncated request isn't valid base64")
+		require.NotEmpty(t, decoded)
+		require.True(t, strings.HasPrefix(rawRequest, string(decoded)), "the truncated request isn't a prefix of the request")
+
+		require.Greater(t, len(outputFile.String()), 20000, "output file should have the complete result")
+		require.NotContains(t, outputFile.String(), `"truncated"`)
+		require.False(t, event.Truncated, "the written event should not be modified")
+	})
+
+	t.Run("Small", func(t *testing.T) {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Host: "https://example.com", Request: "GET / HTTP/1.1"}))
+
+		var request struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		require.NoError(t, json.Unmarshal(received, &request))
+		require.NotContains(t, request.Context, "truncated")
+		require.NotEmpty(t, request.Context["request"])
+	})
+}
+
+func TestTruncateEncoded(t *testing.T) {
+	value := base64.StdEncoding.EncodeToString([]byte("HTTP/1.1 200 OK\r\n\r\n" + strings.Repeat("\x00\xff", 100)))
+	for _, size := range []int{0, 3, 4, 5, 10, 63, 100} {
+		truncated := truncateEncoded(value, size)
+		require.LessOrEqual(t, len(truncated), size)
+		decoded, err := base64.StdEncoding.DecodeString(truncated)
+		require.NoError(t, err, "size %d", size)
+		original, _ := base64.StdEncoding.DecodeString(value)
+		require.Equal(t, original[:len(decoded)], decoded, "size %d", size)
+	}
+}
+
+func TestLongestField(t *testing.T) {
+	short, long, empty := "a", "abc", ""
+	require.Equal(t, &long, longestField([]*string{&short, &long, &empty}))
+	require.Nil(t, longestField([]*string{&empty}))
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Transports the alerts are delivered with
+const (
+	// AlertTransportWebhook posts the alerts to the webhook
+	AlertTransportWebhook = "webhook"
+	// AlertTransportPubSub publishes the alerts to a Google Cloud Pub/Sub topic
+	AlertTransportPubSub = "pubsub"
+)
+
+// AlertTransport publishes the alerts to a destination other than the
+// webhook, eg. a message queue. The scan level events are still sent
+// to the webhook.
+type AlertTransport interface {
+	// Publish publishes the payload of an alert, the envelope of the event
+	// along with its context, with the attributes describing it. It may
+	// return before the alert is delivered, done being called with the
+	// outcome of the delivery once known.
+	Publish(ctx context.Context, payload []byte, attributes map[string]string, done func(error))
+	// Close delivers the outstanding alerts and releases the transport,
+	// their done callbacks having been called once it returns.
+	Close() error
+}
+
+// newAlertTransport returns the transport of the alerts, nil when they
+// are posted to the webhook.
+func newAlertTransport(options *types.Options, client *http.Client) (AlertTransport, error) {
+	switch options.AlertTransport {
+	case "", AlertTransportWebhook:
+		return nil, nil
+	case AlertTransportPubSub:
+		return newPubSubTransport(options.PubSubTopic, client)
+	}
+	return nil, fmt.Errorf("invalid alert transport %q: expected %s or %s", options.AlertTransport, AlertTransportWebhook, AlertTransportPubSub)
+}
+
+// publishAlert publishes the alert with the alert transport. It is
+// counted as sent once published, the alerts which couldn't be published
+// are spooled.
+func (w *StandardWriter) publishAlert(alert *alert) {
+	w.logVerbose("Publishing alert for -> %s\n", alert.templateURL)
+
+	payload := alert.body
+	if payload == nil {
+		var err error
+		if payload, err = w.astraRequestBody(alert.eventName(), alert.context, alert.idempotencyKey); err != nil {
+			gologger.Warning().Msgf("Could not publish alert: %s\n", err)
+			return
+		}
+	}
+	if w.alertFormat == AlertFormatMsgpack {
+		converted, err := jsonToMsgpack(payload)
+		if err != nil {
+			gologger.Warning().Msgf("Could not publish alert: %s\n", err)
+			return
+		}
+		payload = converted
+	}
+	attributes := map[string]string{"event": alert.eventName()}
+	if alert.idempotencyKey != "" {
+		attributes["idempotencyKey"] = alert.idempotencyKey
+	}
+	w.alertTransport.Publish(w.deliveryContext(), payload, attributes, func(err error) {
+		if err != nil {
+			gologger.Warning().Msgf("Could not publish alert for %s: %s\n", alert.templateURL, err)
+			w.metrics.publishFailures.Add(1)
+			w.spoolAlert(alert)
+			return
+		}
+		w.metrics.alertsSent.Add(1)
+	})
+}
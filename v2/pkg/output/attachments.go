@@ -0,0 +1,201 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	b64 "encoding/base64"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// Stores the request and response of the alerts are attached to
+const (
+	// AttachmentStoreFile writes the attachments under the store response directory
+	AttachmentStoreFile = "file"
+	// AttachmentStoreHTTP uploads the attachments to a blob store with http PUT requests
+	AttachmentStoreHTTP = "http"
+)
+
+// AttachmentStore stores the request and response of the alerts outside
+// of their payload, the alerts carry the references to them instead.
+type AttachmentStore interface {
+	// Put stores the attachment under the name returning its reference,
+	// a path or an url the receivers can fetch it from.
+	Put(ctx context.Context, name string, data []byte) (string, error)
+}
+
+// newAttachmentStore returns the store of the attachments, nil when the
+// request and response are inlined in the alerts.
+func newAttachmentStore(options *types.Options, client *http.Client) (AttachmentStore, error) {
+	switch options.AttachmentStore {
+	case "":
+		return nil, nil
+	case AttachmentStoreFile:
+		if options.StoreResponseDir == "" {
+			return nil, errors.New("the file attachment store requires a store response directory")
+		}
+		return &fileAttachmentStore{dir: filepath.Join(options.StoreResponseDir, "attachments")}, nil
+	case AttachmentStoreHTTP:
+		base, err := url.Parse(options.AttachmentURL)
+		if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+			return nil, fmt.Errorf("invalid attachment url %q: expected an http or https url", options.AttachmentURL)
+		}
+		return &httpAttachmentStore{base: base, client: client}, nil
+	}
+	return nil, fmt.Errorf("invalid attachment store %q: expected %s or %s", options.AttachmentStore, AttachmentStoreFile, AttachmentStoreHTTP)
+}
+
+// fileAttachmentStore writes the attachments to a folder, referenced by their path
+type fileAttachmentStore struct {
+	dir string
+}
+
+// Put writes the attachment to the folder, overwriting a previous one
+func (s *fileAttachmentStore) Put(ctx context.Context, name string, data []byte) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", errors.Wrap(err, "could not create attachments folder")
+	}
+	filename := filepath.Join(s.dir, name)
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", errors.Wrap(err, "could not write attachment")
+	}
+	return filename, nil
+}
+
+// httpAttachmentStore uploads the attachments under a base url with PUT
+// requests. The query of the base url, eg. a pre-signed token, is sent
+// with the uploads but left out of the references.
+type httpAttachmentStore struct {
+	base   *url.URL
+	client *http.Client
+}
+
+// Put uploads the attachment returning its url
+func (s *httpAttachmentStore) Put(ctx context.Context, name string, data []byte) (string, error) {
+	target := *s.base
+	target.Path = path.Join("/", s.base.Path, name)
+	target.RawPath = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return "", errors.Wrap(err, "could not create attachment request")
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "could not upload attachment")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("could not upload attachment: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	target.RawQuery = ""
+	return target.String(), nil
+}
+
+// resolveAttachments stores the request and response of the alert, or of
+// the findings of a batch, as attachments when delivering it, formatting
+// its context again with their references. It returns true if the alert
+// changed, the alerts whose proofs couldn't be stored are sent as is.
+func (w *StandardWriter) resolveAttachments(alert *alert) bool {
+	if findings := alert.findings; findings != nil {
+		alert.findings = nil
+		changed := false
+		for _, finding := range findings {
+			if w.resolveAttachments(finding) {
+				changed = true
+			}
+		}
+		if !changed {
+			return false
+		}
+		data, err := batchContext(alert.host, findings)
+		if err != nil {
+			gologger.Warning().Msgf("Could not marshal batch of findings of %s: %s\n", alert.host, err)
+			return false
+		}
+		alert.context = data
+		return true
+	}
+
+	event := alert.attachments
+	if event == nil {
+		return false
+	}
+	// the alert is resolved once, whatever its retries
+	alert.attachments = nil
+	if !w.attachProofs(event) {
+		return false
+	}
+	data, err := w.formatJSON(event)
+	if err != nil {
+		gologger.Warning().Msgf("Could not format alert with attachments: %s\n", err)
+		return false
+	}
+	if w.maxAlertBytes > 0 {
+		if data, err = w.fitAlertContext(event, data, alert.eventName(), alert.idempotencyKey); err != nil {
+			gologger.Warning().Msgf("Could not format alert with attachments: %s\n", err)
+			return false
+		}
+	}
+	if w.webhookTemplate != nil {
+		body, err := w.renderAlert(event, alert.eventName(), alert.idempotencyKey)
+		if err != nil {
+			gologger.Warning().Msgf("Could not render alert with attachments: %s\n", err)
+			return false
+		}
+		alert.body = body
+	}
+	alert.context = data
+	return true
+}
+
+// attachProofs stores the request and response of the event as
+// attachments, replacing them by their references. It returns true if
+// any was stored, the proofs which couldn't be stored stay inline.
+func (w *StandardWriter) attachProofs(event *ResultEvent) bool {
+	name := sanitizeFileName(event.EventID)
+	attached := false
+	if reference, ok := w.storeAttachment(name+"-request.txt", event.Request); ok {
+		event.Request, event.RequestAttachment = "", reference
+		attached = true
+	}
+	if reference, ok := w.storeAttachment(name+"-response.txt", event.Response); ok {
+		event.Response, event.ResponseAttachment = "", reference
+		attached = true
+	}
+	return attached
+}
+
+// storeAttachment stores the decoded base64 proof returning its reference
+func (w *StandardWriter) storeAttachment(name, encoded string) (string, bool) {
+	if encoded == "" {
+		return "", false
+	}
+	data, err := b64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	reference, err := w.attachmentStore.Put(w.deliveryContext(), name, data)
+	if err != nil {
+		gologger.Warning().Msgf("Could not store %s, keeping it inline: %s\n", name, err)
+		return "", false
+	}
+	return reference, true
+}
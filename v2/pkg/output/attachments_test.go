@@ -0,0 +1,177 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestStandardWriterAttachments(t *testing.T) {
+	var mu sync.Mutex
+	var alerts []map[string]interface{}
+	webhook := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		alerts = append(alerts, body.Context)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	const request = "GET /.git/config HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	const response = "HTTP/1.1 200 OK\r\nServer: test\r\n\r\n[core]\n"
+	writeAttached := func(t *testing.T, store AttachmentStore) (map[string]interface{}, *testWriteCloser) {
+		alerts = nil
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(webhook.URL)
+		w.outputFile = outputFile
+		w.jsonReqResp = true
+		w.noReconstruction, w.noWebhookRecon = true, true
+		w.attachmentStore = store
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://example.com", Request: request, Response: response}))
+		require.Len(t, alerts, 1)
+		return alerts[0], outputFile
+	}
+
+	dir := t.TempDir()
+	alert, outputFile := writeAttached(t, &fileAttachmentStore{dir: dir})
+	require.NotContains(t, alert, "request", "the request was inlined in the alert")
+	require.NotContains(t, alert, "response", "the response was inlined in the alert")
+	requestPath, responsePath := alert["request-attachment"].(string), alert["response-attachment"].(string)
+	require.Equal(t, dir, filepath.Dir(requestPath))
+	stored, err := os.ReadFile(requestPath)
+	require.NoError(t, err)
+	require.Equal(t, request, string(stored))
+	stored, err = os.ReadFile(responsePath)
+	require.NoError(t, err)
+	require.Equal(t, response, string(stored))
+
+	var written ResultEvent
+	require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &written))
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte(request)), written.Request, "the output lost the inline request")
+	require.Empty(t, written.RequestAttachment)
+
+	t.Run("HTTP", func(t *testing.T) {
+		var uploadsMu sync.Mutex
+		uploads := make(map[string]string)
+		var queries []string
+		blobs := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPut, r.Method)
+			data, _ := io.ReadAll(r.Body)
+			uploadsMu.Lock()
+			uploads[r.URL.Path] = string(data)
+			queries = append(queries, r.URL.RawQuery)
+			uploadsMu.Unlock()
+			rw.WriteHeader(http.StatusCreated)
+		}))
+		defer blobs.Close()
+
+		store, err := newAttachmentStore(&types.Options{AttachmentStore: AttachmentStoreHTTP, AttachmentURL: blobs.URL + "/proofs/?sig=secret"}, http.DefaultClient)
+		require.NoError(t, err)
+		alert, _ := writeAttached(t, store)
+
+		requestURL := alert["request-attachment"].(string)
+		require.True(t, strings.HasPrefix(requestURL, blobs.URL+"/proofs/"), requestURL)
+		require.NotContains(t, requestURL, "secret", "the signature of the base url leaked into the alert")
+		require.Equal(t, request, uploads[strings.TrimPrefix(requestURL, blobs.URL)])
+		require.Equal(t, response, uploads[strings.TrimPrefix(alert["response-attachment"].(string), blobs.URL)])
+		require.Equal(t, []string{"sig=secret", "sig=secret"}, queries)
+		require.NotContains(t, alert, "request")
+	})
+
+	t.Run("UploadFailure", func(t *testing.T) {
+		blobs := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			http.Error(rw, "bucket not found", http.StatusNotFound)
+		}))
+		defer blobs.Close()
+
+		logs := captureLogs(t)
+		store, err := newAttachmentStore(&types.Options{AttachmentStore: AttachmentStoreHTTP, AttachmentURL: blobs.URL}, http.DefaultClient)
+		require.NoError(t, err)
+		alert, _ := writeAttached(t, store)
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte(request)), alert["request"], "the request wasn't kept inline")
+		require.NotContains(t, alert, "request-attachment")
+		require.Contains(t, logs.String(), "bucket not found")
+	})
+
+	t.Run("Queued", func(t *testing.T) {
+		release := make(chan struct{})
+		blobs := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			<-release
+			rw.WriteHeader(http.StatusCreated)
+		}))
+		defer blobs.Close()
+
+		alerts = nil
+		store, err := newAttachmentStore(&types.Options{AttachmentStore: AttachmentStoreHTTP, AttachmentURL: blobs.URL}, http.DefaultClient)
+		require.NoError(t, err)
+		w := newTestWriter(webhook.URL)
+		w.outputFile = &testWriteCloser{}
+		w.jsonReqResp = true
+		w.noReconstruction, w.noWebhookRecon = true, true
+		w.attachmentStore = store
+		w.alertQueue = newAlertQueue(10, 1, DropPolicyBlock, w.deliverAlert)
+
+		written := make(chan error, 1)
+		go func() {
+			written <- w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://example.com", Request: request, Response: response})
+		}()
+		select {
+		case err := <-written:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			close(release)
+			t.Fatal("the write waited for the attachments to be stored")
+		}
+		close(release)
+		require.True(t, w.alertQueue.close(5*time.Second))
+		require.Len(t, alerts, 1)
+		require.True(t, strings.HasPrefix(alerts[0]["request-attachment"].(string), blobs.URL), "the queued alert lost the attachments")
+		require.NotContains(t, alerts[0], "request")
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		alerts = nil
+		dir := t.TempDir()
+		w := newTestWriter(webhook.URL)
+		w.outputFile = &testWriteCloser{}
+		w.jsonReqResp = true
+		w.noReconstruction, w.noWebhookRecon = true, true
+		w.attachmentStore = &fileAttachmentStore{dir: dir}
+		w.alertBatcher = newAlertBatcher(2, w.dispatchAlert)
+		for _, id := range []string{"git-config", "env-file"} {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: id, Host: "https://example.com", Request: request, Response: response}))
+		}
+		require.Len(t, alerts, 1)
+		findings := alerts[0]["findings"].([]interface{})
+		require.Len(t, findings, 2)
+		for _, finding := range findings {
+			finding := finding.(map[string]interface{})
+			require.NotContains(t, finding, "request", "the batched finding kept the request inline")
+			require.Equal(t, dir, filepath.Dir(finding["request-attachment"].(string)))
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := newAttachmentStore(&types.Options{AttachmentStore: "s3"}, http.DefaultClient)
+		require.ErrorContains(t, err, `invalid attachment store "s3"`)
+		_, err = newAttachmentStore(&types.Options{AttachmentStore: AttachmentStoreHTTP, AttachmentURL: "ftp://blobs.example.com"}, http.DefaultClient)
+		require.ErrorContains(t, err, "expected an http or https url")
+		_, err = newAttachmentStore(&types.Options{AttachmentStore: AttachmentStoreFile}, http.DefaultClient)
+		require.ErrorContains(t, err, "requires a store response directory")
+	})
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"bytes"
+	"sync"
+
+	b64 "encoding/base64"
+)
+
+// maxPooledBufferSize is the maximum capacity of the buffers returned to
+// the pool, the buffers grown by huge responses are left to the gc.
+const maxPooledBufferSize = 4 << 20
+
+// bufferPool pools the buffers used to format the results and to
+// reconstruct their responses, sparing an allocation per write.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer resets the buffer and returns it to the pool. The contents of
+// the buffer mustn't be used afterwards.
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > maxPooledBufferSize {
+		return
+	}
+	buffer.Reset()
+	bufferPool.Put(buffer)
+}
+
+// encodeBase64 returns the base64 encoding of the value. The value is
+// encoded in a pooled buffer, only the returned string is allocated.
+func encodeBase64(value string) string {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	size := b64.StdEncoding.EncodedLen(len(value))
+	buffer.Grow(len(value) + size)
+	buffer.WriteString(value)
+	data := buffer.Bytes()[:len(value)+size]
+	b64.StdEncoding.Encode(data[len(value):], data[:len(value)])
+	return string(data[len(value):])
+}
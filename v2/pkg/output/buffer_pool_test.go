@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeBase64(t *testing.T) {
+	for _, value := range []string{"", "a", "ab", "abc", "HTTP/1.1 200 OK\r\n\r\n", strings.Repeat("é<p>", 1000)} {
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte(value)), encodeBase64(value))
+	}
+	value := strings.Repeat("<p>body</p>\n", 1000)
+	allocs := testing.AllocsPerRun(100, func() { _ = encodeBase64(value) })
+	require.Less(t, allocs, float64(2), "more than the returned string was allocated")
+}
+
+func TestPutBuffer(t *testing.T) {
+	buffer := getBuffer()
+	buffer.WriteString("stale")
+	putBuffer(buffer)
+	require.Equal(t, 0, buffer.Len(), "the buffer wasn't reset")
+
+	large := bytes.NewBuffer(make([]byte, 0, maxPooledBufferSize+1))
+	large.WriteString("stale")
+	putBuffer(large)
+	require.Equal(t, "stale", large.String(), "a buffer over the maximum size was pooled")
+}
+
+func TestStandardWriterPooledBuffers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	stdout := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	w.stdout = stdout
+	w.jsonReqResp = true
+	w.responseBody = true
+	w.prettyJSON = true
+
+	// the responses of concurrent writes mustn't leak into each other
+	// through the reused buffers
+	responses := make(map[string]string)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		templateID := fmt.Sprintf("template-%d", i)
+		body := strings.Repeat(templateID+"\n", 100*(i+1))
+		responses[templateID] = "HTTP version: 1.1\nStatus code: 200\n\n" + body
+		wg.Add(1)
+		go func(templateID, body string) {
+			defer wg.Done()
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: templateID, Response: "HTTP/1.1 200 OK\r\n\r\n" + body}))
+		}(templateID, body)
+	}
+	wg.Wait()
+
+	decoder := json.NewDecoder(strings.NewReader(outputFile.String()))
+	for i := 0; i < 20; i++ {
+		var result ResultEvent
+		require.NoError(t, decoder.Decode(&result))
+		response, err := base64.StdEncoding.DecodeString(result.Response)
+		require.NoError(t, err)
+		require.Equal(t, responses[result.TemplateID], string(response), result.TemplateID)
+	}
+	require.Equal(t, 20, strings.Count(stdout.String(), "\n"), "the stdout lines weren't terminated")
+}
+
+func BenchmarkEncodeBase64(b *testing.B) {
+	value := strings.Repeat("<p>body</p>\n", 1000)
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = encodeBase64(value)
+		}
+	})
+	b.Run("Allocated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+	})
+}
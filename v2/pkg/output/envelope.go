@@ -0,0 +1,82 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Versions of the envelope wrapping the webhook events
+const (
+	// EnvelopeVersionV1 wraps the event context along with the meta,
+	// the event name and idempotency key being part of the meta.
+	EnvelopeVersionV1 = "v1"
+	// EnvelopeVersionV2 wraps the event context along with the meta and
+	// a separate event describing it, along with the envelope version.
+	EnvelopeVersionV2 = "v2"
+)
+
+// envelopeVersionHeader is the header carrying the envelope version of the webhook payloads
+const envelopeVersionHeader = "X-Envelope-Version"
+
+// validateEnvelopeVersion validates the version of the webhook envelope
+func validateEnvelopeVersion(version string) error {
+	switch version {
+	case "", EnvelopeVersionV1, EnvelopeVersionV2:
+		return nil
+	}
+	return fmt.Errorf("invalid envelope version %q: expected %s or %s", version, EnvelopeVersionV1, EnvelopeVersionV2)
+}
+
+// astraEnvelopeV2 is the v2 envelope of the webhook events
+type astraEnvelopeV2 struct {
+	Version string          `json:"version"`
+	Event   astraEventV2    `json:"event"`
+	Meta    AstraMeta       `json:"meta"`
+	Context json.RawMessage `json:"context"`
+}
+
+// astraEventV2 describes the event of a v2 envelope
+type astraEventV2 struct {
+	Type           string    `json:"type"`
+	IdempotencyKey string    `json:"idempotencyKey,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+// astraRequestBody returns the astra request of the event wrapping the
+// context in the envelope of the configured version.
+func (w *StandardWriter) astraRequestBody(event string, context json.RawMessage, idempotencyKey string) ([]byte, error) {
+	var envelope interface{}
+	meta := w.AstraMeta
+	if w.envelopeVersion == EnvelopeVersionV2 {
+		// the event is described by its own field instead of the meta,
+		// which defaults to the alert event
+		meta.Event, meta.IdempotencyKey = "", ""
+		envelope = astraEnvelopeV2{
+			Version: EnvelopeVersionV2,
+			Event:   astraEventV2{Type: event, IdempotencyKey: idempotencyKey, Time: w.currentTime()},
+			Meta:    meta,
+			Context: context,
+		}
+	} else {
+		meta.Event = event
+		meta.IdempotencyKey = idempotencyKey
+		envelope = AstraAlertRequest{Meta: meta, Context: context}
+	}
+
+	postBody, err := jsonEncoder.Marshal(envelope)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal astra request")
+	}
+	return postBody, nil
+}
+
+// envelopeVersionName returns the version of the envelope, v1 if not set
+func (w *StandardWriter) envelopeVersionName() string {
+	if w.envelopeVersion == "" {
+		return EnvelopeVersionV1
+	}
+	return w.envelopeVersion
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestWebhookEnvelopeVersion(t *testing.T) {
+	var body, header string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, header = string(data), r.Header.Get(envelopeVersionHeader)
+	}))
+	defer ts.Close()
+
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	// the meta is built as by NewStandardWriter, with the alert event
+	meta := AstraMeta{Event: "alert", AuditId: "audit-id", JobId: "job-id", ScanId: "scan-id", Hostname: "vm"}
+	tests := []struct {
+		version  string
+		header   string
+		expected string
+	}{
+		{"", EnvelopeVersionV1, `{"meta":{"event":"scan.resumed","auditId":"audit-id","jobId":"job-id","scanId":"scan-id","hostname":"vm","idempotencyKey":"key"},"context":{"reason":"resumed"}}`},
+		{EnvelopeVersionV1, EnvelopeVersionV1, `{"meta":{"event":"scan.resumed","auditId":"audit-id","jobId":"job-id","scanId":"scan-id","hostname":"vm","idempotencyKey":"key"},"context":{"reason":"resumed"}}`},
+		{EnvelopeVersionV2, EnvelopeVersionV2, `{"version":"v2","event":{"type":"scan.resumed","idempotencyKey":"key","time":"2023-04-01T12:00:00Z"},"meta":{"auditId":"audit-id","jobId":"job-id","scanId":"scan-id","hostname":"vm"},"context":{"reason":"resumed"}}`},
+	}
+	for _, test := range tests {
+		t.Run("Version"+test.version, func(t *testing.T) {
+			w := newTestWriter(ts.URL)
+			w.AstraMeta = meta
+			w.envelopeVersion = test.version
+			w.now = func() time.Time { return now }
+
+			resp, err := w.sendAstraEvent(w.deliveryContext(), "scan.resumed", []byte(`{"reason":"resumed"}`), "key")
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.JSONEq(t, test.expected, body)
+			require.Equal(t, test.header, header)
+		})
+	}
+
+	t.Run("Alert", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		w.AstraMeta = meta
+		w.envelopeVersion = EnvelopeVersionV2
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.Contains(t, body, `"version":"v2","event":{"type":"alert","idempotencyKey":"`)
+		require.Contains(t, body, `"context":{"template-id":"git-config"`)
+		require.NotContains(t, body, `"meta":{"event"`)
+	})
+
+	t.Run("ScanEvents", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+		}))
+		w, err := NewStandardWriter(&types.Options{EnvelopeVersion: EnvelopeVersionV2})
+		require.NoError(t, err)
+		w.Start()
+
+		var envelope struct {
+			Event astraEventV2 `json:"event"`
+			Meta  AstraMeta    `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &envelope))
+		require.Equal(t, "scan.started", envelope.Event.Type)
+		require.Empty(t, envelope.Meta.Event, "the meta contradicts the event type")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{EnvelopeVersion: "v3"})
+		require.ErrorContains(t, err, `invalid envelope version "v3"`)
+	})
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Categories of the request errors, aggregating the failures of a scan
+const (
+	ErrorCategoryTimeout = "timeout"
+	ErrorCategoryDNS     = "dns"
+	ErrorCategoryTLS     = "tls"
+	ErrorCategoryRefused = "refused"
+	ErrorCategoryReset   = "reset"
+	ErrorCategoryOther   = "other"
+)
+
+// errorCategoryMessages classifies the errors flattened into strings by
+// the protocol clients, their types being lost, from their message.
+var errorCategoryMessages = []struct {
+	substring string
+	category  string
+}{
+	{"no such host", ErrorCategoryDNS},
+	{"could not resolve host", ErrorCategoryDNS},
+	{"no address found for host", ErrorCategoryDNS},
+	{"timeout", ErrorCategoryTimeout},
+	{"deadline exceeded", ErrorCategoryTimeout},
+	{"connection refused", ErrorCategoryRefused},
+	{"actively refused", ErrorCategoryRefused},
+	{"connection reset", ErrorCategoryReset},
+	{"tls:", ErrorCategoryTLS},
+	{"x509:", ErrorCategoryTLS},
+}
+
+// errorCategory returns the category of the request error, empty for no
+// error. The dns errors are checked first as their timeouts are dns
+// failures rather than target ones.
+func errorCategory(err error) string {
+	if err == nil {
+		return ""
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorCategoryDNS
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorCategoryTimeout
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorCategoryRefused
+	}
+	if errors.Is(err, syscall.ECONNRESET) {
+		return ErrorCategoryReset
+	}
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalidCert      x509.CertificateInvalidError
+		hostnameErr      x509.HostnameError
+		recordHeaderErr  tls.RecordHeaderError
+	)
+	if errors.As(err, &unknownAuthority) || errors.As(err, &invalidCert) || errors.As(err, &hostnameErr) || errors.As(err, &recordHeaderErr) {
+		return ErrorCategoryTLS
+	}
+	message := strings.ToLower(err.Error())
+	for _, match := range errorCategoryMessages {
+		if strings.Contains(message, match.substring) {
+			return match.category
+		}
+	}
+	return ErrorCategoryOther
+}
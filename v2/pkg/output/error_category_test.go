@@ -0,0 +1,86 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestErrorCategory(t *testing.T) {
+	t.Run("Timeout", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer ts.Close()
+
+		_, err := (&http.Client{Timeout: 20 * time.Millisecond}).Get(ts.URL)
+		require.Error(t, err)
+		require.Equal(t, ErrorCategoryTimeout, errorCategory(err), err.Error())
+	})
+
+	t.Run("DNS", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := net.DefaultResolver.LookupHost(ctx, "nuclei-error-category.invalid")
+		require.Error(t, err)
+		require.Equal(t, ErrorCategoryDNS, errorCategory(fmt.Errorf("could not dial: %w", err)), err.Error())
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		_, err = net.DialTimeout("tcp", address, time.Second)
+		require.Error(t, err)
+		require.Equal(t, ErrorCategoryRefused, errorCategory(err), err.Error())
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		_, err := http.Get(ts.URL)
+		require.Error(t, err)
+		require.Equal(t, ErrorCategoryTLS, errorCategory(err), err.Error())
+	})
+
+	t.Run("Messages", func(t *testing.T) {
+		tests := map[string]string{
+			"dial tcp: lookup example.invalid: no such host":                 ErrorCategoryDNS,
+			"context deadline exceeded (Client.Timeout exceeded)":            ErrorCategoryTimeout,
+			"dial tcp 127.0.0.1:8080: connect: connection refused":           ErrorCategoryRefused,
+			"read tcp 127.0.0.1:8080: read: connection reset by peer":        ErrorCategoryReset,
+			"remote error: tls: handshake failure":                           ErrorCategoryTLS,
+			"could not read template: invalid character '}' in yaml mapping": ErrorCategoryOther,
+		}
+		for message, category := range tests {
+			require.Equal(t, category, errorCategory(errors.New(message)), message)
+		}
+		require.Empty(t, errorCategory(nil))
+	})
+}
+
+func TestStandardWriterRequestCategory(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	traceWriter := &testWriteCloser{}
+	w, err := NewStandardWriter(&types.Options{})
+	require.NoError(t, err)
+	w.traceFile = traceWriter
+
+	w.Request("first.yaml", "https://example.com", "http", nil)
+	w.Request("second.yaml", "https://example.com", "http", fmt.Errorf("giving up after 2 attempts: %w", &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}))
+	require.Equal(t, `{"template":"first.yaml","input":"https://example.com","error":"none","type":"http"}`+"\n"+
+		`{"template":"second.yaml","input":"https://example.com","error":"lookup example.com: no such host","category":"dns","type":"http"}`+"\n", traceWriter.String())
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// resultEventFields are the top level json fields of a result event
+var resultEventFields = jsonFieldNames(reflect.TypeOf(ResultEvent{}))
+
+// jsonFieldNames returns the json field names of the struct type
+func jsonFieldNames(structType reflect.Type) map[string]struct{} {
+	fields := make(map[string]struct{})
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = struct{}{}
+	}
+	return fields
+}
+
+// validateFieldNames returns an error listing the names which are not result event fields
+func validateFieldNames(names []string) error {
+	var unknown []string
+	for _, name := range names {
+		if _, ok := resultEventFields[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown result fields: %s", strings.Join(unknown, ", "))
+}
+
+// fieldFilter keeps or drops top level fields of the marshaled result events
+type fieldFilter struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+// newFieldFilter creates a new field filter validating the field names.
+// It returns nil if no fields are included or excluded.
+func newFieldFilter(include, exclude []string) (*fieldFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	if err := validateFieldNames(append(append([]string{}, include...), exclude...)); err != nil {
+		return nil, err
+	}
+	filter := &fieldFilter{exclude: make(map[string]struct{})}
+	if len(include) > 0 {
+		filter.include = make(map[string]struct{})
+		for _, name := range include {
+			filter.include[name] = struct{}{}
+		}
+	}
+	for _, name := range exclude {
+		filter.exclude[name] = struct{}{}
+	}
+	return filter, nil
+}
+
+// apply filters the fields of the marshaled event. As the fields are
+// re-marshaled from a map they are written sorted by name.
+func (f *fieldFilter) apply(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := jsonEncoder.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name := range fields {
+		if _, ok := f.exclude[name]; ok {
+			delete(fields, name)
+			continue
+		}
+		if _, ok := f.include[name]; f.include != nil && !ok {
+			delete(fields, name)
+		}
+	}
+	return jsonEncoder.Marshal(fields)
+}
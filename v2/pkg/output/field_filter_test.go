@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestFieldFilter(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	event := func() *ResultEvent {
+		return &ResultEvent{
+			TemplateID: "git-config",
+			Host:       "https://example.com",
+			Matched:    "https://example.com/.git/config",
+			Request:    "GET /.git/config HTTP/1.1",
+			Response:   "HTTP/1.1 200 OK",
+		}
+	}
+
+	t.Run("Exclude", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		w.jsonReqResp = true
+		filter, err := newFieldFilter(nil, []string{"request", "response"})
+		require.NoError(t, err)
+		w.fieldFilter = filter
+
+		require.NoError(t, w.Write(event()))
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(received.Context, &fields))
+		require.NotContains(t, fields, "request")
+		require.NotContains(t, fields, "response")
+		require.Equal(t, "git-config", fields["template-id"])
+		require.Equal(t, "https://example.com", fields["host"])
+		require.Equal(t, "https://example.com/.git/config", fields["matched-at"])
+		require.Contains(t, fields, "timestamp")
+	})
+
+	t.Run("Include", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		filter, err := newFieldFilter([]string{"template-id", "matched-at"}, nil)
+		require.NoError(t, err)
+		w.fieldFilter = filter
+
+		require.NoError(t, w.Write(event()))
+		require.JSONEq(t, `{"template-id":"git-config","matched-at":"https://example.com/.git/config"}`, string(received.Context))
+	})
+
+	t.Run("UnknownFields", func(t *testing.T) {
+		_, err := newFieldFilter([]string{"template-id", "templateid"}, []string{"responses", "FileToIndexPosition"})
+		require.EqualError(t, err, "unknown result fields: FileToIndexPosition, responses, templateid")
+
+		_, err = NewStandardWriter(&types.Options{ExcludeFields: []string{"responses"}})
+		require.ErrorContains(t, err, "unknown result fields: responses")
+	})
+}
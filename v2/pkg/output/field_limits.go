@@ -0,0 +1,94 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	b64 "encoding/base64"
+)
+
+// fieldLimitMarker ends the values truncated to their field limit
+const fieldLimitMarker = "... [truncated]"
+
+// minFieldLimit is the minimum limit of a field, leaving room for the
+// marker in the values decoded from base64.
+const minFieldLimit = 64
+
+// limitedFields are the fields whose size can be limited
+var limitedFields = map[string]struct{}{
+	"request": {}, "response": {}, "raw-response": {}, "curl-command": {}, "extracted-results": {},
+}
+
+// validateFieldLimits validates the names and sizes of the field limits
+func validateFieldLimits(limits map[string]int) error {
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := limitedFields[name]; !ok {
+			return fmt.Errorf("invalid field limit: %q can't be limited, expected request, response, raw-response, curl-command or extracted-results", name)
+		}
+		if limits[name] < minFieldLimit {
+			return fmt.Errorf("invalid field limit: %q limit %d is below %d bytes", name, limits[name], minFieldLimit)
+		}
+	}
+	return nil
+}
+
+// truncateField truncates the value to limit bytes, the marker included,
+// without splitting a character.
+func truncateField(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	size := limit - len(fieldLimitMarker)
+	for i := 0; i < utf8.UTFMax-1 && size > 0 && !utf8.RuneStart(value[size]); i++ {
+		size--
+	}
+	return value[:size] + fieldLimitMarker
+}
+
+// encodeField returns the base64 encoded value of the field, truncated
+// before its encoding so it stays decodable and fits in the field limit.
+// The event is marked as truncated if the value was.
+func (w *StandardWriter) encodeField(event *ResultEvent, field, value string) string {
+	if limit := w.fieldLimits[field]; limit > 0 && b64.StdEncoding.EncodedLen(len(value)) > limit {
+		value = truncateField(value, limit/4*3)
+		event.Truncated = true
+	}
+	return encodeBase64(value)
+}
+
+// limitFields truncates the fields of the event written as is to their
+// limits. The extracted results are limited as a whole, the results
+// past the limit are dropped.
+func (w *StandardWriter) limitFields(event *ResultEvent) {
+	if limit := w.fieldLimits["curl-command"]; limit > 0 && len(event.CURLCommand) > limit {
+		event.CURLCommand = truncateField(event.CURLCommand, limit)
+		event.Truncated = true
+	}
+	limit := w.fieldLimits["extracted-results"]
+	if limit <= 0 {
+		return
+	}
+	size := 0
+	for i, result := range event.ExtractedResults {
+		if size+len(result) <= limit {
+			size += len(result)
+			continue
+		}
+		// the results are copied as they are shared with the caller
+		limited := append([]string{}, event.ExtractedResults[:i]...)
+		if remaining := limit - size; remaining > len(fieldLimitMarker) {
+			limited = append(limited, truncateField(result, remaining))
+		} else {
+			limited = append(limited, fieldLimitMarker)
+		}
+		event.ExtractedResults = limited
+		event.Truncated = true
+		return
+	}
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestTruncateField(t *testing.T) {
+	require.Equal(t, "short", truncateField("short", 64))
+
+	truncated := truncateField(strings.Repeat("a", 100), 64)
+	require.Len(t, truncated, 64)
+	require.True(t, strings.HasSuffix(truncated, fieldLimitMarker))
+
+	truncated = truncateField(strings.Repeat("é", 50), 64)
+	require.LessOrEqual(t, len(truncated), 64)
+	require.True(t, utf8.ValidString(truncated), "a character was split")
+}
+
+func TestStandardWriterFieldLimits(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	writeLimited := func(t *testing.T, limits map[string]int, event *ResultEvent) map[string]interface{} {
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.outputFile = outputFile
+		w.jsonReqResp = true
+		w.noReconstruction = true
+		w.noWebhookRecon = true
+		w.fieldLimits = limits
+		require.NoError(t, w.Write(event))
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+		return fields
+	}
+	decode := func(t *testing.T, value interface{}) string {
+		decoded, err := base64.StdEncoding.DecodeString(value.(string))
+		require.NoError(t, err)
+		return string(decoded)
+	}
+	newEvent := func() *ResultEvent {
+		return &ResultEvent{
+			TemplateID:       "git-config",
+			Request:          strings.Repeat("q", 1000),
+			Response:         strings.Repeat("r", 1000),
+			ExtractedResults: []string{strings.Repeat("x", 300), strings.Repeat("y", 300), strings.Repeat("z", 300)},
+		}
+	}
+
+	t.Run("Request", func(t *testing.T) {
+		fields := writeLimited(t, map[string]int{"request": 400}, newEvent())
+		require.LessOrEqual(t, len(fields["request"].(string)), 400)
+		request := decode(t, fields["request"])
+		require.True(t, strings.HasSuffix(request, fieldLimitMarker))
+		require.Equal(t, strings.Repeat("r", 1000), decode(t, fields["response"]), "the response was truncated")
+		require.Len(t, fields["extracted-results"], 3)
+		require.Equal(t, true, fields["truncated"])
+	})
+
+	t.Run("Response", func(t *testing.T) {
+		fields := writeLimited(t, map[string]int{"response": 200}, newEvent())
+		require.LessOrEqual(t, len(fields["response"].(string)), 200)
+		require.True(t, strings.HasSuffix(decode(t, fields["response"]), fieldLimitMarker))
+		require.Equal(t, strings.Repeat("q", 1000), decode(t, fields["request"]), "the request was truncated")
+		require.Equal(t, true, fields["truncated"])
+	})
+
+	t.Run("ExtractedResults", func(t *testing.T) {
+		event := newEvent()
+		extracted := event.ExtractedResults
+		fields := writeLimited(t, map[string]int{"extracted-results": 500}, event)
+		results := fields["extracted-results"].([]interface{})
+		require.Len(t, results, 2, "the results past the limit weren't dropped")
+		require.Equal(t, strings.Repeat("x", 300), results[0])
+		require.Len(t, results[1], 200)
+		require.True(t, strings.HasSuffix(results[1].(string), fieldLimitMarker))
+		require.Equal(t, strings.Repeat("y", 300), extracted[1], "the results of the caller were modified")
+		require.Equal(t, strings.Repeat("q", 1000), decode(t, fields["request"]))
+		require.Equal(t, strings.Repeat("r", 1000), decode(t, fields["response"]))
+	})
+
+	t.Run("All", func(t *testing.T) {
+		fields := writeLimited(t, map[string]int{"request": 100, "response": 300, "extracted-results": 700}, newEvent())
+		require.Len(t, decode(t, fields["request"]), 75)
+		require.Len(t, decode(t, fields["response"]), 225)
+		results := fields["extracted-results"].([]interface{})
+		require.Len(t, results, 3)
+		require.Len(t, results[2], 100)
+	})
+
+	t.Run("WithinLimits", func(t *testing.T) {
+		fields := writeLimited(t, map[string]int{"request": 4096, "response": 4096, "extracted-results": 4096}, newEvent())
+		require.Equal(t, strings.Repeat("q", 1000), decode(t, fields["request"]))
+		require.Equal(t, strings.Repeat("r", 1000), decode(t, fields["response"]))
+		require.Len(t, fields["extracted-results"], 3)
+		require.NotContains(t, fields, "truncated")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{FieldLimits: map[string]int{"template-id": 100}})
+		require.ErrorContains(t, err, `"template-id" can't be limited`)
+		_, err = NewStandardWriter(&types.Options{FieldLimits: map[string]int{"response": 10}})
+		require.ErrorContains(t, err, `"response" limit 10 is below 64 bytes`)
+	})
+}
@@ -1,14 +1,33 @@
 package output
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"sync"
+	"time"
+
+	fileutil "github.com/projectdiscovery/utils/file"
 )
 
-// fileWriter is a concurrent file based output writer.
+// fileWriter is a concurrent file based output writer. Each record is
+// written to the file whole, the buffer only saves a write for the newline,
+// so the results written survive a crash of the scan.
 type fileWriter struct {
-	file *os.File
-	mu   sync.Mutex
+	path   string
+	file   *os.File
+	buffer *bufio.Writer
+	mu     sync.Mutex
+
+	// maxSize and maxAge roll the file over to path.1, path.2, etc.
+	// once it grows past the size or gets older than the age.
+	maxSize  int64
+	maxAge   time.Duration
+	size     int64
+	openedAt time.Time
+	rotation int
 }
 
 // NewFileOutputWriter creates a new buffered writer for a file
@@ -16,34 +35,153 @@ func newFileOutputWriter(file string, resume bool) (*fileWriter, error) {
 	var output *os.File
 	var err error
 	if resume {
-		output, err = os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		output, err = os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
 	} else {
 		output, err = os.Create(file)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &fileWriter{file: output}, nil
+	if resume {
+		// an abruptly killed scan can leave a half written last line
+		if err := truncatePartialLine(output); err != nil {
+			output.Close()
+			return nil, err
+		}
+	}
+	info, err := output.Stat()
+	if err != nil {
+		output.Close()
+		return nil, err
+	}
+	return &fileWriter{path: file, file: output, buffer: bufio.NewWriter(output), size: info.Size(), openedAt: time.Now()}, nil
+}
+
+// truncatePartialLine truncates the file after its last complete line
+func truncatePartialLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+
+	chunk := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := end - int64(len(chunk))
+		if start < 0 {
+			start = 0
+		}
+		n, err := file.ReadAt(chunk[:end-start], start)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if index := bytes.LastIndexByte(chunk[:n], '\n'); index != -1 {
+			return truncateFile(file, size, start+int64(index)+1)
+		}
+		end = start
+	}
+	return truncateFile(file, size, 0)
+}
+
+// truncateFile truncates the file of given size to length if it is shorter
+func truncateFile(file *os.File, size, length int64) error {
+	if length == size {
+		return nil
+	}
+	return file.Truncate(length)
 }
 
 // WriteString writes an output to the underlying file
 func (w *fileWriter) Write(data []byte) (int, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	if _, err := w.file.Write(data); err != nil {
+
+	// records are terminated by a newline, added unless already present
+	length := len(data)
+	newline := !bytes.HasSuffix(data, []byte("\n"))
+	if newline {
+		length++
+	}
+	if w.shouldRotate(int64(length)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := w.buffer.Write(data); err != nil {
 		return 0, err
 	}
-	if _, err := w.file.Write([]byte("\n")); err != nil {
+	if newline {
+		if err := w.buffer.WriteByte('\n'); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.buffer.Flush(); err != nil {
 		return 0, err
 	}
-	return len(data) + 1, nil
+	w.size += int64(length)
+	return length, nil
+}
+
+// shouldRotate returns true if writing length bytes to the file
+// requires rolling it over first. Empty files are never rotated.
+func (w *fileWriter) shouldRotate(length int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.maxSize > 0 && w.size+length > w.maxSize {
+		return true
+	}
+	return w.maxAge > 0 && time.Since(w.openedAt) >= w.maxAge
+}
+
+// rotate moves the file to the next free path.N and starts a new file
+func (w *fileWriter) rotate() error {
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	var rotated string
+	for {
+		w.rotation++
+		rotated = fmt.Sprintf("%s.%d", w.path, w.rotation)
+		if !fileutil.FileExists(rotated) {
+			break
+		}
+	}
+	if err := os.Rename(w.path, rotated); err != nil {
+		return err
+	}
+	output, err := os.Create(w.path)
+	if err != nil {
+		return err
+	}
+	w.file = output
+	w.buffer.Reset(output)
+	w.size = 0
+	w.openedAt = time.Now()
+	return nil
+}
+
+// Flush syncs the underlying file to disk
+func (w *fileWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	}
+	return w.file.Sync()
 }
 
 // Close closes the underlying writer flushing everything to disk
 func (w *fileWriter) Close() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	//nolint:errcheck // we don't care whether sync failed or succeeded.
+	//nolint:errcheck // we don't care whether flush or sync failed or succeeded.
+	w.buffer.Flush()
+	//nolint:errcheck
 	w.file.Sync()
 	return w.file.Close()
 }
@@ -0,0 +1,161 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWriterResume(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{"PartialLastLine", "{\"template-id\":\"first\"}\n{\"template-id\":\"sec", "{\"template-id\":\"first\"}\n{\"template-id\":\"new\"}\n"},
+		{"CompleteLastLine", "{\"template-id\":\"first\"}\n", "{\"template-id\":\"first\"}\n{\"template-id\":\"new\"}\n"},
+		{"OnlyPartialLine", "{\"template-id\":\"fi", "{\"template-id\":\"new\"}\n"},
+		{"Empty", "", "{\"template-id\":\"new\"}\n"},
+		{"LongPartialLine", "{\"template-id\":\"first\"}\n" + strings.Repeat("A", 10000), "{\"template-id\":\"first\"}\n{\"template-id\":\"new\"}\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "output.jsonl")
+			require.NoError(t, os.WriteFile(path, []byte(test.existing), 0644))
+
+			writer, err := newFileOutputWriter(path, true)
+			require.NoError(t, err)
+			_, err = writer.Write([]byte(`{"template-id":"new"}`))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(data))
+		})
+	}
+
+	t.Run("MissingFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "output.jsonl")
+		writer, err := newFileOutputWriter(path, true)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		require.FileExists(t, path)
+	})
+}
+
+func TestFileWriterNewlineSeparated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.jsonl")
+	writer, err := newFileOutputWriter(path, false)
+	require.NoError(t, err)
+
+	for _, record := range []string{`{"id":1}`, "{\"id\":2}\n", `{"id":3}`} {
+		_, err = writer.Write([]byte(record))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n", string(data))
+}
+
+func TestFileWriterUnflushed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.jsonl")
+	writer, err := newFileOutputWriter(path, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	// a crashed scan never flushes nor closes the writer
+	for _, record := range []string{`{"id":1}`, `{"id":2}`} {
+		_, err = writer.Write([]byte(record))
+		require.NoError(t, err)
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "{\"id\":1}\n{\"id\":2}\n", string(data), "the results were lost in the buffer")
+}
+
+func TestFileWriterRotation(t *testing.T) {
+	line := []byte(`{"template-id":"test"}`)
+
+	t.Run("Size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "output.jsonl")
+		writer, err := newFileOutputWriter(path, false)
+		require.NoError(t, err)
+		writer.maxSize = int64(2 * (len(line) + 1))
+
+		for i := 0; i < 5; i++ {
+			_, err = writer.Write(line)
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+
+		expected := map[string]int{path + ".1": 2, path + ".2": 2, path: 1}
+		for file, lines := range expected {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			require.Equal(t, lines, strings.Count(string(data), "\n"), file)
+		}
+		require.NoFileExists(t, path+".3")
+	})
+
+	t.Run("Age", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "output.jsonl")
+		writer, err := newFileOutputWriter(path, false)
+		require.NoError(t, err)
+		writer.maxAge = time.Hour
+
+		_, err = writer.Write(line)
+		require.NoError(t, err)
+		_, err = writer.Write(line)
+		require.NoError(t, err)
+		require.NoFileExists(t, path+".1", "file was rotated before its age")
+
+		writer.openedAt = time.Now().Add(-2 * time.Hour)
+		_, err = writer.Write(line)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		rotated, err := os.ReadFile(path + ".1")
+		require.NoError(t, err)
+		require.Equal(t, 2, strings.Count(string(rotated), "\n"))
+		active, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, string(line)+"\n", string(active))
+	})
+
+	t.Run("ExistingRotations", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "output.jsonl")
+		require.NoError(t, os.WriteFile(path, append(line, '\n'), 0644))
+		require.NoError(t, os.WriteFile(path+".1", []byte("previous\n"), 0644))
+
+		writer, err := newFileOutputWriter(path, true)
+		require.NoError(t, err)
+		writer.maxSize = int64(len(line) + 1)
+		_, err = writer.Write(line)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		previous, err := os.ReadFile(path + ".1")
+		require.NoError(t, err)
+		require.Equal(t, "previous\n", string(previous), "existing rotation was overwritten")
+		require.FileExists(t, path+".2")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "output.jsonl")
+		writer, err := newFileOutputWriter(path, false)
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			_, err = writer.Write(line)
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.Close())
+		require.NoFileExists(t, path+".1")
+	})
+}
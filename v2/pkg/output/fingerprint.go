@@ -0,0 +1,60 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultFingerprintFields are the fields of the result hashed into its
+// fingerprint unless configured otherwise.
+var defaultFingerprintFields = []string{"template-id", "host", "matched-at", "matcher-name"}
+
+// fingerprintFields are the fields of the result which can be hashed into
+// its fingerprint, by their json name.
+var fingerprintFields = map[string]func(event *ResultEvent) string{
+	"template-id":       func(event *ResultEvent) string { return event.TemplateID },
+	"type":              func(event *ResultEvent) string { return event.Type },
+	"host":              func(event *ResultEvent) string { return event.Host },
+	"path":              func(event *ResultEvent) string { return event.Path },
+	"matched-at":        func(event *ResultEvent) string { return event.Matched },
+	"matcher-name":      func(event *ResultEvent) string { return event.MatcherName },
+	"extractor-name":    func(event *ResultEvent) string { return event.ExtractorName },
+	"extracted-results": func(event *ResultEvent) string { return strings.Join(event.ExtractedResults, "\x00") },
+	"ip":                func(event *ResultEvent) string { return event.IP },
+}
+
+// newFingerprintFields validates the fields hashed into the fingerprint
+// of the results, returning the default ones if none are given.
+func newFingerprintFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return defaultFingerprintFields, nil
+	}
+	for _, field := range fields {
+		if _, ok := fingerprintFields[field]; !ok {
+			names := make([]string, 0, len(fingerprintFields))
+			for name := range fingerprintFields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("invalid fingerprint field %q: expected one of %s", field, strings.Join(names, ","))
+		}
+	}
+	return fields, nil
+}
+
+// fingerprint returns the SHA-256 of the fields of the result, identical
+// for the same finding across scans. Each field is hashed with its name
+// so values moving from one field to another change the fingerprint.
+func fingerprint(event *ResultEvent, fields []string) string {
+	hash := sha256.New()
+	for _, field := range fields {
+		hash.Write([]byte(field))
+		hash.Write([]byte{'='})
+		hash.Write([]byte(fingerprintFields[field](event)))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestFingerprint(t *testing.T) {
+	newEvent := func() *ResultEvent {
+		return &ResultEvent{TemplateID: "git-config", Host: "https://example.com", Matched: "https://example.com/.git/config", MatcherName: "config", IP: "93.184.216.34"}
+	}
+	expected := fingerprint(newEvent(), defaultFingerprintFields)
+	require.Len(t, expected, 64)
+	require.Equal(t, expected, fingerprint(newEvent(), defaultFingerprintFields), "identical findings have different fingerprints")
+
+	changed := newEvent()
+	changed.IP = "127.0.0.1"
+	require.Equal(t, expected, fingerprint(changed, defaultFingerprintFields), "fields not hashed changed the fingerprint")
+
+	for _, change := range []func(event *ResultEvent){
+		func(event *ResultEvent) { event.TemplateID = "git-head" },
+		func(event *ResultEvent) { event.Host = "https://example.org" },
+		func(event *ResultEvent) { event.Matched = "https://example.com/.git/HEAD" },
+		func(event *ResultEvent) { event.MatcherName = "" },
+		// a value moving to another field is another finding
+		func(event *ResultEvent) { event.MatcherName, event.Matched = event.Matched+"config", "" },
+	} {
+		event := newEvent()
+		change(event)
+		require.NotEqual(t, expected, fingerprint(event, defaultFingerprintFields), "different findings have identical fingerprints")
+	}
+
+	t.Run("Fields", func(t *testing.T) {
+		fields, err := newFingerprintFields([]string{"template-id", "ip"})
+		require.NoError(t, err)
+		require.Equal(t, fingerprint(newEvent(), fields), fingerprint(&ResultEvent{TemplateID: "git-config", IP: "93.184.216.34"}, fields))
+		require.NotEqual(t, fingerprint(newEvent(), fields), fingerprint(changed, fields))
+
+		fields, err = newFingerprintFields(nil)
+		require.NoError(t, err)
+		require.Equal(t, defaultFingerprintFields, fields)
+
+		_, err = newFingerprintFields([]string{"template-id", "timestamp"})
+		require.ErrorContains(t, err, `invalid fingerprint field "timestamp"`)
+	})
+}
+
+func TestStandardWriterFingerprint(t *testing.T) {
+	var context map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		context = body.Context
+	}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	w.fingerprintFields = defaultFingerprintFields
+	event := &ResultEvent{TemplateID: "git-config", Host: "https://example.com", Matched: "https://example.com/.git/config"}
+	require.NoError(t, w.Write(event))
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+	require.Equal(t, event.Fingerprint, fields["fingerprint"])
+	require.Equal(t, event.Fingerprint, context["fingerprint"])
+
+	// the timestamp differs between the writes, unlike the fingerprint
+	again := &ResultEvent{TemplateID: "git-config", Host: "https://example.com", Matched: "https://example.com/.git/config"}
+	require.NoError(t, w.Write(again))
+	require.Equal(t, event.Fingerprint, again.Fingerprint)
+
+	t.Run("Disabled", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.NotContains(t, context, "fingerprint")
+	})
+
+	t.Run("InvalidField", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{Fingerprint: true, FingerprintFields: []string{"timestamp"}})
+		require.ErrorContains(t, err, `invalid fingerprint field "timestamp"`)
+	})
+}
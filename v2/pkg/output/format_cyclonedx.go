@@ -0,0 +1,229 @@
+package output
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/catalog/config"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+// cyclonedxSpecVersion is the version of the CycloneDX specification of the reports
+const cyclonedxSpecVersion = "1.5"
+
+// cyclonedxBOM is a CycloneDX Vulnerability Disclosure Report, the scanned
+// targets are its components and the findings its vulnerabilities.
+type cyclonedxBOM struct {
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber"`
+	Version         int                      `json:"version"`
+	Metadata        cyclonedxMetadata        `json:"metadata"`
+	Components      []cyclonedxComponent     `json:"components"`
+	Vulnerabilities []cyclonedxVulnerability `json:"vulnerabilities"`
+}
+
+type cyclonedxMetadata struct {
+	Timestamp string         `json:"timestamp"`
+	Tools     cyclonedxTools `json:"tools"`
+}
+
+type cyclonedxTools struct {
+	Components []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type cyclonedxVulnerability struct {
+	BOMRef      string              `json:"bom-ref,omitempty"`
+	ID          string              `json:"id"`
+	Source      *cyclonedxSource    `json:"source,omitempty"`
+	Ratings     []cyclonedxRating   `json:"ratings"`
+	CWEs        []int               `json:"cwes,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Created     string              `json:"created,omitempty"`
+	Affects     []cyclonedxAffect   `json:"affects"`
+	Properties  []cyclonedxProperty `json:"properties,omitempty"`
+}
+
+type cyclonedxSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type cyclonedxRating struct {
+	Score    float64 `json:"score,omitempty"`
+	Severity string  `json:"severity"`
+	Method   string  `json:"method,omitempty"`
+	Vector   string  `json:"vector,omitempty"`
+}
+
+type cyclonedxAffect struct {
+	Ref string `json:"ref"`
+}
+
+type cyclonedxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cyclonedxReport accumulates the findings written as a CycloneDX report
+// on close, instead of a line per result.
+type cyclonedxReport struct {
+	mu              sync.Mutex
+	vulnerabilities []cyclonedxVulnerability
+	targets         map[string]struct{}
+}
+
+// newCycloneDXReport creates a new empty report
+func newCycloneDXReport() *cyclonedxReport {
+	return &cyclonedxReport{targets: make(map[string]struct{})}
+}
+
+// add records the result as a vulnerability affecting its target
+func (r *cyclonedxReport) add(event *ResultEvent) {
+	target := event.Host
+	if target == "" {
+		target = event.Matched
+	}
+	vulnerability := cyclonedxVulnerability{
+		BOMRef:      event.EventID,
+		ID:          event.TemplateID,
+		Ratings:     []cyclonedxRating{cyclonedxRatingOf(event)},
+		CWEs:        cyclonedxCWEs(event.CWE),
+		Description: event.Info.Description,
+		Affects:     []cyclonedxAffect{{Ref: target}},
+		Properties:  []cyclonedxProperty{{Name: "nuclei:template-id", Value: event.TemplateID}},
+	}
+	if len(event.CVE) > 0 {
+		vulnerability.ID = event.CVE[0]
+		vulnerability.Source = &cyclonedxSource{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + event.CVE[0]}
+	} else if event.TemplateURL != "" {
+		vulnerability.Source = &cyclonedxSource{Name: "nuclei-templates", URL: event.TemplateURL}
+	}
+	if !event.Timestamp.IsZero() {
+		vulnerability.Created = event.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if event.Matched != "" {
+		vulnerability.Properties = append(vulnerability.Properties, cyclonedxProperty{Name: "nuclei:matched-at", Value: event.Matched})
+	}
+	if event.MatcherName != "" {
+		vulnerability.Properties = append(vulnerability.Properties, cyclonedxProperty{Name: "nuclei:matcher-name", Value: event.MatcherName})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.vulnerabilities = append(r.vulnerabilities, vulnerability)
+	r.targets[target] = struct{}{}
+}
+
+// document returns the report of the recorded findings, the components
+// are sorted by target for a deterministic output.
+func (r *cyclonedxReport) document(now time.Time) *cyclonedxBOM {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	targets := make([]string, 0, len(r.targets))
+	for target := range r.targets {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	document := &cyclonedxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cyclonedxSpecVersion,
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cyclonedxMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools:     cyclonedxTools{Components: []cyclonedxComponent{{Type: "application", Name: "nuclei", Version: config.Version}}},
+		},
+		Components:      make([]cyclonedxComponent, 0, len(targets)),
+		Vulnerabilities: append([]cyclonedxVulnerability{}, r.vulnerabilities...),
+	}
+	for _, target := range targets {
+		document.Components = append(document.Components, cyclonedxComponent{Type: "application", BOMRef: target, Name: target})
+	}
+	return document
+}
+
+// cyclonedxRatingOf returns the rating of the result, its severity along
+// with its cvss score when classified.
+func cyclonedxRatingOf(event *ResultEvent) cyclonedxRating {
+	rating := cyclonedxRating{Severity: cyclonedxSeverity(event.Info.SeverityHolder.Severity)}
+	if event.CVSSScore <= 0 {
+		return rating
+	}
+	rating.Score = event.CVSSScore
+	rating.Method = "other"
+	if classification := event.Info.Classification; classification != nil && classification.CVSSMetrics != "" {
+		rating.Vector = classification.CVSSMetrics
+		// the templates write the vector with or without its CVSS: prefix
+		switch version := strings.TrimPrefix(rating.Vector, "CVSS:"); {
+		case strings.HasPrefix(version, "3.1/"):
+			rating.Method = "CVSSv31"
+		case strings.HasPrefix(version, "3.0/"):
+			rating.Method = "CVSSv3"
+		case strings.HasPrefix(version, "4.0/"):
+			rating.Method = "CVSSv4"
+		}
+	}
+	return rating
+}
+
+// cyclonedxSeverity returns the CycloneDX severity of the nuclei one
+func cyclonedxSeverity(value severity.Severity) string {
+	switch value {
+	case severity.Info, severity.Low, severity.Medium, severity.High, severity.Critical:
+		return value.String()
+	}
+	return "unknown"
+}
+
+// cyclonedxCWEs returns the numbers of the CWE-<n> ids, skipping the
+// malformed ones.
+func cyclonedxCWEs(ids []string) []int {
+	var cwes []int
+	for _, id := range ids {
+		if number, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(id), "CWE-")); err == nil {
+			cwes = append(cwes, number)
+		}
+	}
+	return cwes
+}
+
+// newUUID returns a random version 4 uuid
+func newUUID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+// writeCycloneDXReport writes the report to stdout and the output file
+func (w *StandardWriter) writeCycloneDXReport() error {
+	data, err := jsonEncoder.MarshalIndent(w.cyclonedxReport.document(time.Now()), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if w.stdout != nil {
+		_, _ = w.stdout.Write(data)
+	}
+	if w.outputFile != nil {
+		return w.writeOutputFile(w.outputFile, data)
+	}
+	return nil
+}
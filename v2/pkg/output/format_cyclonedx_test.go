@@ -0,0 +1,141 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestStandardWriterCycloneDX(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	w.cyclonedxReport = newCycloneDXReport()
+
+	require.NoError(t, w.Write(&ResultEvent{
+		TemplateID:  "CVE-2021-44228",
+		TemplateURL: "https://templates.nuclei.sh/public/CVE-2021-44228",
+		Info: model.Info{
+			Description:    "Apache Log4j2 JNDI injection",
+			SeverityHolder: severity.Holder{Severity: severity.Critical},
+			Classification: &model.Classification{CVSSMetrics: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", CVSSScore: 10},
+		},
+		CVE:         []string{"CVE-2021-44228"},
+		CWE:         []string{"CWE-502", "CWE-invalid"},
+		CVSSScore:   10,
+		MatcherName: "dns",
+		Host:        "https://example.com",
+		Matched:     "https://example.com/api/login",
+	}))
+	require.NoError(t, w.Write(&ResultEvent{
+		TemplateID:  "git-config",
+		TemplateURL: "https://templates.nuclei.sh/public/git-config",
+		Info:        model.Info{SeverityHolder: severity.Holder{Severity: severity.Medium}},
+		Host:        "https://another.example.com",
+		Matched:     "https://another.example.com/.git/config",
+	}))
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "tech-detect", Host: "https://example.com"}))
+	require.Empty(t, outputFile.String(), "the results were written before the report")
+
+	require.NoError(t, w.writeCycloneDXReport())
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &document))
+
+	require.Equal(t, "CycloneDX", document["bomFormat"])
+	require.Equal(t, cyclonedxSpecVersion, document["specVersion"])
+	require.Regexp(t, regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), document["serialNumber"])
+	require.Equal(t, float64(1), document["version"])
+
+	metadata := document["metadata"].(map[string]interface{})
+	_, err := time.Parse(time.RFC3339, metadata["timestamp"].(string))
+	require.NoError(t, err)
+	tool := metadata["tools"].(map[string]interface{})["components"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "nuclei", tool["name"])
+
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"type": "application", "bom-ref": "https://another.example.com", "name": "https://another.example.com"},
+		map[string]interface{}{"type": "application", "bom-ref": "https://example.com", "name": "https://example.com"},
+	}, document["components"], "the targets weren't deduplicated into sorted components")
+
+	vulnerabilities := document["vulnerabilities"].([]interface{})
+	require.Len(t, vulnerabilities, 3)
+
+	log4j := vulnerabilities[0].(map[string]interface{})
+	require.Equal(t, "CVE-2021-44228", log4j["id"])
+	require.NotEmpty(t, log4j["bom-ref"])
+	require.Equal(t, map[string]interface{}{"name": "NVD", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-44228"}, log4j["source"])
+	require.Equal(t, []interface{}{map[string]interface{}{
+		"score":    float64(10),
+		"severity": "critical",
+		"method":   "CVSSv31",
+		"vector":   "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H",
+	}}, log4j["ratings"])
+	require.Equal(t, []interface{}{float64(502)}, log4j["cwes"])
+	require.Equal(t, "Apache Log4j2 JNDI injection", log4j["description"])
+	_, err = time.Parse(time.RFC3339, log4j["created"].(string))
+	require.NoError(t, err, "the vulnerability wasn't dated")
+	require.Equal(t, []interface{}{map[string]interface{}{"ref": "https://example.com"}}, log4j["affects"])
+	require.Contains(t, log4j["properties"], map[string]interface{}{"name": "nuclei:matched-at", "value": "https://example.com/api/login"})
+
+	gitConfig := vulnerabilities[1].(map[string]interface{})
+	require.Equal(t, "git-config", gitConfig["id"])
+	require.Equal(t, map[string]interface{}{"name": "nuclei-templates", "url": "https://templates.nuclei.sh/public/git-config"}, gitConfig["source"])
+	require.Equal(t, []interface{}{map[string]interface{}{"severity": "medium"}}, gitConfig["ratings"])
+	require.Equal(t, []interface{}{map[string]interface{}{"ref": "https://another.example.com"}}, gitConfig["affects"])
+
+	techDetect := vulnerabilities[2].(map[string]interface{})
+	require.Equal(t, []interface{}{map[string]interface{}{"severity": "unknown"}}, techDetect["ratings"])
+
+	t.Run("Empty", func(t *testing.T) {
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.outputFile = outputFile
+		w.cyclonedxReport = newCycloneDXReport()
+		require.NoError(t, w.writeCycloneDXReport())
+		require.True(t, strings.Contains(outputFile.String(), `"vulnerabilities": []`), "the vulnerabilities of an empty report aren't an empty list")
+	})
+
+	t.Run("Option", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		w, err := NewStandardWriter(&types.Options{OutputFormat: OutputFormatCycloneDX})
+		require.NoError(t, err)
+		require.NotNil(t, w.cyclonedxReport)
+		require.True(t, w.json, "the alerts aren't formatted as json")
+	})
+}
+
+func TestCycloneDXRating(t *testing.T) {
+	tests := []struct {
+		metrics string
+		method  string
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "CVSSv31"},
+		{"3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "CVSSv31"},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "CVSSv3"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", "CVSSv4"},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", "other"},
+		{"", "other"},
+	}
+	for _, test := range tests {
+		rating := cyclonedxRatingOf(&ResultEvent{
+			Info:      model.Info{SeverityHolder: severity.Holder{Severity: severity.High}, Classification: &model.Classification{CVSSMetrics: test.metrics}},
+			CVSSScore: 7.5,
+		})
+		require.Equal(t, test.method, rating.Method, test.metrics)
+		require.Equal(t, "high", rating.Severity)
+		require.Equal(t, 7.5, rating.Score)
+	}
+}
@@ -0,0 +1,185 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+// Formats of the results written to stdout and the output file
+const (
+	// OutputFormatJSON writes the results in the native json format
+	OutputFormatJSON = "json"
+	// OutputFormatECS writes the results as Elastic Common Schema documents
+	OutputFormatECS = "ecs"
+	// OutputFormatCycloneDX writes a CycloneDX vulnerability disclosure
+	// report of the results on close
+	OutputFormatCycloneDX = "cyclonedx"
+)
+
+// ecsVersion is the version of the Elastic Common Schema of the documents
+const ecsVersion = "8.11.0"
+
+// validateOutputFormat validates the format of the results
+func validateOutputFormat(format string) error {
+	switch format {
+	case "", OutputFormatJSON, OutputFormatECS, OutputFormatCycloneDX:
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q: expected %s, %s or %s", format, OutputFormatJSON, OutputFormatECS, OutputFormatCycloneDX)
+}
+
+// ecsDocument is a result as an Elastic Common Schema document
+type ecsDocument struct {
+	Timestamp     string           `json:"@timestamp"`
+	Message       string           `json:"message,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	ECS           ecsVersionField  `json:"ecs"`
+	Event         ecsEvent         `json:"event"`
+	Vulnerability ecsVulnerability `json:"vulnerability"`
+	URL           ecsURL           `json:"url"`
+	Host          ecsHost          `json:"host"`
+	Rule          *ecsRule         `json:"rule,omitempty"`
+	TLS           *ecsTLS          `json:"tls,omitempty"`
+	// Nuclei is the native result, for the fields without an ECS counterpart
+	Nuclei json.RawMessage `json:"nuclei"`
+}
+
+type ecsVersionField struct {
+	Version string `json:"version"`
+}
+
+type ecsEvent struct {
+	ID       string   `json:"id,omitempty"`
+	Kind     string   `json:"kind"`
+	Category []string `json:"category"`
+	Type     []string `json:"type"`
+	Severity int      `json:"severity"`
+	Sequence uint64   `json:"sequence,omitempty"`
+}
+
+type ecsVulnerability struct {
+	ID          string           `json:"id"`
+	Description string           `json:"description,omitempty"`
+	Reference   []string         `json:"reference,omitempty"`
+	Severity    string           `json:"severity"`
+	Score       *ecsScore        `json:"score,omitempty"`
+	Scanner     ecsScannerVendor `json:"scanner"`
+	Enumeration string           `json:"enumeration,omitempty"`
+	Category    []string         `json:"category,omitempty"`
+}
+
+type ecsScore struct {
+	Base float64 `json:"base"`
+}
+
+type ecsScannerVendor struct {
+	Vendor string `json:"vendor"`
+}
+
+type ecsURL struct {
+	Full string `json:"full,omitempty"`
+}
+
+type ecsHost struct {
+	Name string `json:"name,omitempty"`
+	IP   string `json:"ip,omitempty"`
+}
+
+type ecsRule struct {
+	Name string `json:"name"`
+}
+
+type ecsTLS struct {
+	Server ecsTLSServer `json:"server"`
+}
+
+type ecsTLSServer struct {
+	Subject   string  `json:"subject,omitempty"`
+	Issuer    string  `json:"issuer,omitempty"`
+	NotBefore string  `json:"not_before,omitempty"`
+	NotAfter  string  `json:"not_after,omitempty"`
+	X509      ecsX509 `json:"x509"`
+}
+
+type ecsX509 struct {
+	AlternativeNames []string `json:"alternative_names,omitempty"`
+}
+
+// formatECS formats the output as an Elastic Common Schema document
+func (w *StandardWriter) formatECS(output *ResultEvent) ([]byte, error) {
+	native, err := w.formatJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	severityValue := output.Info.SeverityHolder.Severity
+	document := &ecsDocument{
+		Timestamp: output.Timestamp.UTC().Format(time.RFC3339Nano),
+		Message:   output.Info.Name,
+		Tags:      output.Tags,
+		ECS:       ecsVersionField{Version: ecsVersion},
+		Event: ecsEvent{
+			ID:       output.EventID,
+			Kind:     "alert",
+			Category: []string{"vulnerability"},
+			Type:     []string{"info"},
+			Severity: ecsSeverity(severityValue),
+			Sequence: output.Seq,
+		},
+		Vulnerability: ecsVulnerability{
+			ID:          output.TemplateID,
+			Description: output.Info.Description,
+			Reference:   output.Info.Reference.ToSlice(),
+			Severity:    severityValue.String(),
+			Scanner:     ecsScannerVendor{Vendor: "ProjectDiscovery"},
+			Category:    output.CWE,
+		},
+		URL:    ecsURL{Full: output.Matched},
+		Host:   ecsHost{Name: resultHostname(output.Host), IP: output.IP},
+		Nuclei: native,
+	}
+	if document.URL.Full == "" {
+		document.URL.Full = output.Host
+	}
+	if len(output.CVE) > 0 {
+		document.Vulnerability.Enumeration = "CVE"
+	}
+	if output.CVSSScore > 0 {
+		document.Vulnerability.Score = &ecsScore{Base: output.CVSSScore}
+	}
+	if output.MatcherName != "" {
+		document.Rule = &ecsRule{Name: output.MatcherName}
+	} else if output.ExtractorName != "" {
+		document.Rule = &ecsRule{Name: output.ExtractorName}
+	}
+	if tls := output.TLS; tls != nil {
+		document.TLS = &ecsTLS{Server: ecsTLSServer{
+			Subject:   tls.Subject,
+			Issuer:    tls.Issuer,
+			NotBefore: ecsTime(tls.NotBefore),
+			NotAfter:  ecsTime(tls.NotAfter),
+			X509:      ecsX509{AlternativeNames: tls.SubjectAN},
+		}}
+	}
+	return jsonEncoder.Marshal(document)
+}
+
+// ecsTime formats the time as an ECS date, empty for the zero time
+func ecsTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339Nano)
+}
+
+// ecsSeverity returns the numeric event.severity of the severity, from 0
+// for an unknown severity to 5 for a critical one.
+func ecsSeverity(value severity.Severity) int {
+	if value >= severity.Info && value <= severity.Critical {
+		return int(value)
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/stringslice"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestFormatECS(t *testing.T) {
+	w := &StandardWriter{}
+	data, err := w.formatECS(&ResultEvent{
+		TemplateID:  "CVE-2021-44228",
+		Info:        model.Info{Name: "Log4j RCE", Description: "Apache Log4j2 JNDI injection", Reference: stringslice.NewRaw("https://logging.apache.org"), SeverityHolder: severity.Holder{Severity: severity.Critical}},
+		CVE:         []string{"CVE-2021-44228"},
+		CWE:         []string{"CWE-502"},
+		CVSSScore:   10,
+		MatcherName: "dns",
+		Type:        "http",
+		Host:        "https://example.com:8443",
+		Matched:     "https://example.com:8443/api/login",
+		IP:          "93.184.216.34",
+		Timestamp:   time.Date(2023, 3, 14, 10, 0, 0, 0, time.FixedZone("CET", 3600)),
+		EventID:     "event-id",
+		Seq:         4,
+	})
+	require.NoError(t, err)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &document))
+	require.Equal(t, "2023-03-14T09:00:00Z", document["@timestamp"])
+	require.Equal(t, "Log4j RCE", document["message"])
+	require.Equal(t, ecsVersion, document["ecs"].(map[string]interface{})["version"])
+
+	event := document["event"].(map[string]interface{})
+	require.Equal(t, "event-id", event["id"])
+	require.Equal(t, "alert", event["kind"])
+	require.Equal(t, []interface{}{"vulnerability"}, event["category"])
+	require.Equal(t, float64(5), event["severity"])
+	require.Equal(t, float64(4), event["sequence"])
+
+	vulnerability := document["vulnerability"].(map[string]interface{})
+	require.Equal(t, "CVE-2021-44228", vulnerability["id"])
+	require.Equal(t, "critical", vulnerability["severity"])
+	require.Equal(t, "Apache Log4j2 JNDI injection", vulnerability["description"])
+	require.Equal(t, []interface{}{"https://logging.apache.org"}, vulnerability["reference"])
+	require.Equal(t, "CVE", vulnerability["enumeration"])
+	require.Equal(t, []interface{}{"CWE-502"}, vulnerability["category"])
+	require.Equal(t, float64(10), vulnerability["score"].(map[string]interface{})["base"])
+
+	require.Equal(t, "https://example.com:8443/api/login", document["url"].(map[string]interface{})["full"])
+	require.Equal(t, map[string]interface{}{"name": "example.com", "ip": "93.184.216.34"}, document["host"])
+	require.Equal(t, "dns", document["rule"].(map[string]interface{})["name"])
+	require.Equal(t, "CVE-2021-44228", document["nuclei"].(map[string]interface{})["template-id"], "native result was not kept")
+
+	t.Run("UnknownSeverity", func(t *testing.T) {
+		data, err := w.formatECS(&ResultEvent{TemplateID: "test", Host: "example.com:80", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Unknown}}})
+		require.NoError(t, err)
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &document))
+		require.Equal(t, float64(0), document["event"].(map[string]interface{})["severity"])
+		require.Equal(t, "example.com:80", document["url"].(map[string]interface{})["full"], "host is the url without a match")
+		require.Equal(t, "example.com", document["host"].(map[string]interface{})["name"])
+		require.NotContains(t, document, "rule")
+	})
+}
+
+func TestStandardWriterOutputFormatECS(t *testing.T) {
+	var context map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		context = body.Context
+	}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFormat = OutputFormatECS
+	w.outputFile = outputFile
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://example.com"}))
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &document))
+	require.Equal(t, "git-config", document["vulnerability"].(map[string]interface{})["id"])
+	require.Equal(t, "git-config", context["template-id"], "the webhook payload is in the native format")
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{OutputFormat: "xml"})
+		require.ErrorContains(t, err, `invalid output format "xml"`)
+	})
+}
@@ -4,11 +4,21 @@ import (
 	jsoniter "github.com/json-iterator/go"
 )
 
+// jsonEncoder is the json encoder used for all the output written by nuclei.
+//
+// It is compatible with the standard library and sorts map keys, so the
+// serialized output of an event is deterministic across runs.
+var jsonEncoder = jsoniter.ConfigCompatibleWithStandardLibrary
+
 // formatJSON formats the output for json based formatting
 func (w *StandardWriter) formatJSON(output *ResultEvent) ([]byte, error) {
 	if !w.jsonReqResp { // don't show request-response in json if not asked
 		output.Request = ""
 		output.Response = ""
 	}
-	return jsoniter.Marshal(output)
+	data, err := jsonEncoder.Marshal(output)
+	if err != nil || w.fieldFilter == nil {
+		return data, err
+	}
+	return w.fieldFilter.apply(data)
 }
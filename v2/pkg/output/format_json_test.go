@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/stringslice"
+)
+
+func TestFormatJSONGolden(t *testing.T) {
+	golden, err := os.ReadFile("testdata/format_json.golden")
+	require.NoError(t, err)
+	golden = bytes.TrimSpace(golden)
+
+	w := &StandardWriter{jsonReqResp: true}
+	for i := 0; i < 20; i++ {
+		data, err := w.formatJSON(newGoldenResultEvent())
+		require.NoError(t, err)
+		require.Equal(t, string(golden), string(data), "serialized output differs from golden file")
+	}
+}
+
+func TestFormatJSONOmitsEmptyFields(t *testing.T) {
+	w := &StandardWriter{}
+	data, err := w.formatJSON(&ResultEvent{
+		TemplateID: "minimal",
+		Type:       "http",
+		Info:       model.Info{SeverityHolder: severity.Holder{Severity: severity.Info}},
+		Timestamp:  time.Date(2023, 3, 14, 10, 0, 0, 0, time.UTC),
+		Request:    "GET / HTTP/1.1",
+		Response:   "HTTP/1.1 200 OK",
+	})
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, jsonEncoder.Unmarshal(data, &fields))
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	require.ElementsMatch(t, []string{"template-id", "info", "type", "timestamp", "matcher-status"}, keys)
+}
+
+func TestStandardWriterPrettyJSON(t *testing.T) {
+	var received AstraAlertRequest
+	var payload []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		payload, _ = io.ReadAll(r.Body)
+		_ = json.Unmarshal(payload, &received)
+	}))
+	defer ts.Close()
+
+	outputWriter := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputWriter
+	w.prettyJSON = true
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "pretty", Host: "https://example.com"}))
+
+	require.Contains(t, outputWriter.String(), "{\n  \"template-id\": \"pretty\",\n")
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(outputWriter.String()), &fields))
+	require.Equal(t, "pretty", fields["template-id"])
+
+	require.NotContains(t, string(payload), "\n")
+	compacted := &bytes.Buffer{}
+	require.NoError(t, json.Compact(compacted, received.Context))
+	require.Equal(t, compacted.String(), string(received.Context), "webhook payload was indented")
+}
+
+// newGoldenResultEvent returns the result event serialized in testdata/format_json.golden
+func newGoldenResultEvent() *ResultEvent {
+	return &ResultEvent{
+		Template:      "http/cves/2021/CVE-2021-44228.yaml",
+		TemplateURL:   "https://github.com/projectdiscovery/nuclei-templates/blob/main/http/cves/2021/CVE-2021-44228.yaml",
+		TemplateID:    "CVE-2021-44228",
+		TemplatePath:  "/root/nuclei-templates/http/cves/2021/CVE-2021-44228.yaml",
+		MatcherName:   "header",
+		Type:          "http",
+		Host:          "https://example.com",
+		Matched:       "https://example.com/api?x=${jndi:ldap://oast.me}",
+		Request:       "R0VUIC8gSFRUUC8xLjE=",
+		Response:      "SFRUUC8xLjEgMjAwIE9L",
+		IP:            "93.184.216.34",
+		MatcherStatus: true,
+		Timestamp:     time.Date(2023, 3, 14, 10, 0, 0, 0, time.UTC),
+		ExtractedResults: []string{
+			"log4j",
+			"2.14.1",
+		},
+		Metadata: map[string]interface{}{
+			"zeta":    "last",
+			"alpha":   "first",
+			"payload": "${jndi:ldap://oast.me}",
+			"count":   3,
+			"nested":  map[string]interface{}{"b": 2, "a": 1},
+		},
+		Info: model.Info{
+			Name:           "Apache Log4j2 Remote Code Injection",
+			Authors:        stringslice.StringSlice{Value: []string{"pdteam", "princechaddha"}},
+			Tags:           stringslice.StringSlice{Value: []string{"cve", "rce", "log4j"}},
+			SeverityHolder: severity.Holder{Severity: severity.Critical},
+			Metadata:       map[string]interface{}{"verified": true, "max-request": 1},
+			Classification: &model.Classification{
+				CVEID:     stringslice.StringSlice{Value: "CVE-2021-44228"},
+				CWEID:     stringslice.StringSlice{Value: "CWE-502"},
+				CVSSScore: 10,
+			},
+		},
+	}
+}
@@ -2,13 +2,18 @@ package output
 
 import (
 	"bytes"
+	"encoding/base64"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
 )
 
 // formatScreen formats the output for showing on screen.
 func (w *StandardWriter) formatScreen(output *ResultEvent) []byte {
+	if w.compactOutput {
+		return w.formatCompact(output)
+	}
 	builder := &bytes.Buffer{}
 
 	if !w.noMetadata {
@@ -78,6 +83,12 @@ func (w *StandardWriter) formatScreen(output *ResultEvent) []byte {
 		builder.WriteString("]")
 	}
 
+	if output.Latency > 0 {
+		builder.WriteString(" [")
+		builder.WriteString(w.aurora.BrightMagenta(strconv.FormatInt(output.Latency, 10) + "ms").String())
+		builder.WriteString("]")
+	}
+
 	// Write meta if any
 	if len(output.Metadata) > 0 {
 		builder.WriteString(" [")
@@ -95,5 +106,62 @@ func (w *StandardWriter) formatScreen(output *ResultEvent) []byte {
 		}
 		builder.WriteString("]")
 	}
+
+	// Write the request and response when asked to, truncated to the limit
+	if w.jsonReqResp {
+		w.writeScreenBody(builder, "Request", output.Request)
+		w.writeScreenBody(builder, "Response", output.Response)
+	}
+	return builder.Bytes()
+}
+
+// screenBodyEllipsis marks a request or response truncated on screen
+const screenBodyEllipsis = "... [truncated]"
+
+// writeScreenBody writes the base64 encoded request or response of the
+// result under its name, truncated to the screen body limit.
+func (w *StandardWriter) writeScreenBody(builder *bytes.Buffer, name, encoded string) {
+	if encoded == "" {
+		return
+	}
+	body, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		body = []byte(encoded)
+	}
+	builder.WriteString("\n")
+	builder.WriteString(w.aurora.Bold("[" + name + "]").String())
+	builder.WriteString("\n")
+	builder.Write(truncateScreenBody(body, w.screenBodyLimit))
+}
+
+// truncateScreenBody truncates the body to limit bytes followed by an
+// ellipsis marker, without splitting a character. A limit of 0 disables it.
+func truncateScreenBody(body []byte, limit int) []byte {
+	if limit <= 0 || len(body) <= limit {
+		return body
+	}
+	// step back to the start of a character split by the limit
+	for i := 0; i < utf8.UTFMax-1 && limit > 0 && !utf8.RuneStart(body[limit]); i++ {
+		limit--
+	}
+	return append(append([]byte{}, body[:limit]...), screenBodyEllipsis...)
+}
+
+// formatCompact formats the output as a single terse line of the form
+// [template-id] [severity] matched-at.
+func (w *StandardWriter) formatCompact(output *ResultEvent) []byte {
+	builder := &bytes.Buffer{}
+
+	builder.WriteRune('[')
+	builder.WriteString(w.aurora.BrightGreen(output.TemplateID).String())
+	builder.WriteString("] [")
+	builder.WriteString(w.severityColors(output.Info.SeverityHolder.Severity))
+	builder.WriteString("] ")
+
+	if output.Matched != "" {
+		builder.WriteString(output.Matched)
+	} else {
+		builder.WriteString(output.Host)
+	}
 	return builder.Bytes()
 }
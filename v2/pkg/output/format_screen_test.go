@@ -0,0 +1,83 @@
+package output
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+func TestFormatScreenCompact(t *testing.T) {
+	w := newTestWriter("")
+	w.compactOutput = true
+
+	event := &ResultEvent{
+		TemplateID:       "git-config",
+		MatcherName:      "word",
+		Type:             "http",
+		Host:             "https://example.com",
+		Matched:          "https://example.com/.git/config",
+		ExtractedResults: []string{"[core]"},
+		Metadata:         map[string]interface{}{"key": "value"},
+		Lines:            []int{1, 2},
+		Info:             model.Info{SeverityHolder: severity.Holder{Severity: severity.Medium}},
+	}
+	line := string(w.formatScreen(event))
+	require.Equal(t, "[git-config] [medium] https://example.com/.git/config", line)
+	require.NotContains(t, line, "\n")
+	require.Equal(t, []string{"[git-config]", "[medium]", "https://example.com/.git/config"}, strings.Fields(line))
+
+	event.Matched = ""
+	require.Equal(t, "[git-config] [medium] https://example.com", string(w.formatScreen(event)))
+
+	w.compactOutput = false
+	require.Contains(t, string(w.formatScreen(event)), "[http]", "default format is not verbose")
+}
+
+func TestFormatScreenBodyLimit(t *testing.T) {
+	response := "HTTP/1.1 200 OK\r\n\r\n" + strings.Repeat("a", 100)
+	write := func(limit int) (string, string) {
+		outputWriter := &testWriteCloser{}
+		w := newTestWriter("")
+		w.json = false
+		w.jsonReqResp = true
+		w.noReconstruction = true
+		w.screenBodyLimit = limit
+		w.outputFile = outputWriter
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Request: "GET / HTTP/1.1", Response: response}))
+
+		jsonWriter := &testWriteCloser{}
+		w.json = true
+		w.outputFile = jsonWriter
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Request: "GET / HTTP/1.1", Response: response}))
+		return outputWriter.String(), jsonWriter.String()
+	}
+
+	t.Run("AtBoundary", func(t *testing.T) {
+		screen, jsonOutput := write(len(response) - 1)
+		require.Contains(t, screen, "[Request]\nGET / HTTP/1.1")
+		require.Contains(t, screen, "[Response]\n"+response[:len(response)-1]+screenBodyEllipsis)
+		require.Contains(t, jsonOutput, base64.StdEncoding.EncodeToString([]byte(response)), "json output was truncated")
+	})
+
+	t.Run("BelowLimit", func(t *testing.T) {
+		screen, _ := write(len(response))
+		require.Contains(t, screen, "[Response]\n"+response)
+		require.NotContains(t, screen, screenBodyEllipsis)
+	})
+
+	t.Run("NoLimit", func(t *testing.T) {
+		screen, _ := write(0)
+		require.Contains(t, screen, "[Response]\n"+response)
+		require.NotContains(t, screen, screenBodyEllipsis)
+	})
+
+	t.Run("MultiByteCharacter", func(t *testing.T) {
+		require.Equal(t, "ab"+screenBodyEllipsis, string(truncateScreenBody([]byte("abécd"), 3)))
+		require.Equal(t, "abé"+screenBodyEllipsis, string(truncateScreenBody([]byte("abécd"), 4)))
+	})
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"net"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// hostFilter drops the results of denied hosts or, when an allow-list is
+// set, of the hosts not in it. Denied hosts win over allowed ones. The
+// hostname and the ip of a result are matched against cidr ranges, eg.
+// 10.0.0.0/8, or glob patterns, eg. *.staging.example.com.
+type hostFilter struct {
+	allow hostPatterns
+	deny  hostPatterns
+}
+
+// hostPatterns is a list of cidr ranges and hostname glob patterns
+type hostPatterns struct {
+	networks []*net.IPNet
+	globs    []string
+}
+
+// newHostFilter creates a new host filter validating the patterns.
+// It returns nil if no hosts are allowed or denied.
+func newHostFilter(allow, deny []string) (*hostFilter, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	allowed, err := parseHostPatterns(allow)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := parseHostPatterns(deny)
+	if err != nil {
+		return nil, err
+	}
+	return &hostFilter{allow: allowed, deny: denied}, nil
+}
+
+// parseHostPatterns parses the patterns, a bare ip address being the
+// range of that single address.
+func parseHostPatterns(patterns []string) (hostPatterns, error) {
+	var parsed hostPatterns
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if strings.Contains(pattern, "/") {
+			_, network, err := net.ParseCIDR(pattern)
+			if err != nil {
+				return parsed, errors.Wrapf(err, "invalid host cidr %q", pattern)
+			}
+			parsed.networks = append(parsed.networks, network)
+			continue
+		}
+		if ip := net.ParseIP(pattern); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			parsed.networks = append(parsed.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return parsed, errors.Wrapf(err, "invalid host pattern %q", pattern)
+		}
+		parsed.globs = append(parsed.globs, pattern)
+	}
+	return parsed, nil
+}
+
+// empty returns true if there are no patterns
+func (p hostPatterns) empty() bool {
+	return len(p.networks) == 0 && len(p.globs) == 0
+}
+
+// matches returns true if any of the hosts matches any of the patterns
+func (p hostPatterns) matches(hosts []string) bool {
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			for _, network := range p.networks {
+				if network.Contains(ip) {
+					return true
+				}
+			}
+		}
+		if matchesAnyPattern(p.globs, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesIPs returns true if the filter has cidr ranges, which only
+// match the ip of the hostname targets.
+func (f *hostFilter) matchesIPs() bool {
+	return len(f.allow.networks) > 0 || len(f.deny.networks) > 0
+}
+
+// allowed returns true if the results of the host and ip should be written
+func (f *hostFilter) allowed(host, ip string) bool {
+	var hosts []string
+	for _, value := range []string{resultHostname(host), ip} {
+		if value != "" {
+			hosts = append(hosts, strings.ToLower(value))
+		}
+	}
+	if f.deny.matches(hosts) {
+		return false
+	}
+	return f.allow.empty() || f.allow.matches(hosts)
+}
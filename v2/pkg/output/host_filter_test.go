@@ -0,0 +1,112 @@
+package output
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestHostFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		deny    []string
+		allowed []string
+	}{
+		{"CIDR", nil, []string{"10.0.0.0/8"}, []string{"https://example.com", "https://staging.example.com", "192.168.1.10:8080", "[2001:db8::1]:443"}},
+		{"IP", nil, []string{"192.168.1.10", "2001:db8::1"}, []string{"https://example.com", "https://staging.example.com", "https://internal.example.com/app"}},
+		{"IPv6CIDR", []string{"2001:db8::/32"}, nil, []string{"[2001:db8::1]:443"}},
+		{"Glob", nil, []string{"*.staging.example.com", "staging.*"}, []string{"https://example.com", "https://internal.example.com/app", "192.168.1.10:8080", "[2001:db8::1]:443"}},
+		{"AllowList", []string{"*.example.com", "192.168.0.0/16"}, nil, []string{"https://staging.example.com", "https://internal.example.com/app", "192.168.1.10:8080"}},
+		{"DenyWins", []string{"*.example.com", "192.168.0.0/16"}, []string{"staging.example.com", "192.168.1.10"}, []string{"https://internal.example.com/app"}},
+	}
+	hosts := map[string]string{
+		"https://example.com":              "93.184.216.34",
+		"https://staging.example.com":      "93.184.216.35",
+		"https://internal.example.com/app": "10.1.2.3",
+		"192.168.1.10:8080":                "",
+		"[2001:db8::1]:443":                "",
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := newHostFilter(test.allow, test.deny)
+			require.NoError(t, err)
+
+			var allowed []string
+			for _, host := range []string{"https://example.com", "https://staging.example.com", "https://internal.example.com/app", "192.168.1.10:8080", "[2001:db8::1]:443"} {
+				if filter.allowed(host, hosts[host]) {
+					allowed = append(allowed, host)
+				}
+			}
+			require.ElementsMatch(t, test.allowed, allowed)
+		})
+	}
+
+	t.Run("NoFilter", func(t *testing.T) {
+		filter, err := newHostFilter(nil, nil)
+		require.NoError(t, err)
+		require.Nil(t, filter)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := newHostFilter([]string{"10.0.0.0/33"}, nil)
+		require.ErrorContains(t, err, `invalid host cidr "10.0.0.0/33"`)
+		_, err = newHostFilter(nil, []string{"staging.[example"})
+		require.ErrorContains(t, err, `invalid host pattern "staging.[example"`)
+	})
+}
+
+func TestStandardWriterHostFilter(t *testing.T) {
+	var alerts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		alerts.Add(1)
+	}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	w.resolver = &testResolver{}
+	filter, err := newHostFilter([]string{"*.example.com"}, []string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	w.hostFilter = filter
+
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://www.example.com", IP: "93.184.216.34"}))
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://internal.example.com", IP: "10.1.2.3"}))
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://example.org"}))
+	require.Equal(t, int32(1), alerts.Load(), "results of filtered hosts were sent to the webhook")
+	require.Contains(t, outputFile.String(), "www.example.com")
+	require.NotContains(t, outputFile.String(), "internal.example.com")
+	require.NotContains(t, outputFile.String(), "example.org")
+
+	t.Run("ResolvedHostname", func(t *testing.T) {
+		alerts.Store(0)
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.outputFile = outputFile
+		w.resolver = &testResolver{addresses: map[string][]string{
+			"internal.example.com": {"10.1.2.3"},
+			"www.example.com":      {"93.184.216.34"},
+		}}
+		filter, err := newHostFilter(nil, []string{"10.0.0.0/8"})
+		require.NoError(t, err)
+		w.hostFilter = filter
+
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://internal.example.com"}))
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://www.example.com"}))
+		require.Equal(t, int32(1), alerts.Load(), "the cidr range didn't match the ip of the hostname")
+		require.NotContains(t, outputFile.String(), "internal.example.com")
+		require.Contains(t, outputFile.String(), "www.example.com")
+	})
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{DenyHosts: []string{"10.0.0.0/40"}})
+		require.ErrorContains(t, err, "invalid host cidr")
+	})
+}
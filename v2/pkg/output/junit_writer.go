@@ -0,0 +1,139 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// junitTestSuites is the root element of a junit report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the findings of a template
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single finding reported as a failed test
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+// junitFailure carries the details of a finding
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitWriter accumulates the findings and writes them as a junit
+// report on close, with a test suite per template.
+type junitWriter struct {
+	mu     sync.Mutex
+	file   *os.File
+	suites map[string][]junitTestCase
+}
+
+// newJUnitWriter creates the junit report file written on close
+func newJUnitWriter(path string) (*junitWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create junit report")
+	}
+	return &junitWriter{file: file, suites: make(map[string][]junitTestCase)}, nil
+}
+
+// Write records the result as a failed test case of its template
+func (j *junitWriter) Write(event *ResultEvent) {
+	matchedAt := event.Matched
+	if matchedAt == "" {
+		matchedAt = event.Host
+	}
+	severity := event.Info.SeverityHolder.Severity.String()
+
+	details := &strings.Builder{}
+	fmt.Fprintf(details, "template: %s\n", event.TemplateID)
+	if event.Info.Name != "" {
+		fmt.Fprintf(details, "name: %s\n", event.Info.Name)
+	}
+	fmt.Fprintf(details, "severity: %s\n", severity)
+	fmt.Fprintf(details, "host: %s\n", event.Host)
+	fmt.Fprintf(details, "matched-at: %s\n", matchedAt)
+	if len(event.ExtractedResults) > 0 {
+		fmt.Fprintf(details, "extracted-results: %s\n", strings.Join(event.ExtractedResults, ", "))
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.suites[event.TemplateID] = append(j.suites[event.TemplateID], junitTestCase{
+		Name:      matchedAt,
+		ClassName: event.TemplateID,
+		Failure: &junitFailure{
+			Message: fmt.Sprintf("[%s] %s", severity, matchedAt),
+			Type:    severity,
+			Text:    details.String(),
+		},
+	})
+}
+
+// report returns the junit report of the recorded results, the test
+// suites are sorted by template id for a deterministic output.
+func (j *junitWriter) report() *junitTestSuites {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	templateIDs := make([]string, 0, len(j.suites))
+	for templateID := range j.suites {
+		templateIDs = append(templateIDs, templateID)
+	}
+	sort.Strings(templateIDs)
+
+	report := &junitTestSuites{Name: "nuclei"}
+	for _, templateID := range templateIDs {
+		cases := j.suites[templateID]
+		report.Suites = append(report.Suites, junitTestSuite{
+			Name:     templateID,
+			Tests:    len(cases),
+			Failures: len(cases),
+			Cases:    cases,
+		})
+		report.Tests += len(cases)
+		report.Failures += len(cases)
+	}
+	return report
+}
+
+// Close writes the junit report and closes the file
+func (j *junitWriter) Close() error {
+	data, err := xml.MarshalIndent(j.report(), "", "  ")
+	if err != nil {
+		j.file.Close()
+		return errors.Wrap(err, "could not marshal junit report")
+	}
+	if _, err := j.file.WriteString(xml.Header); err != nil {
+		j.file.Close()
+		return errors.Wrap(err, "could not write junit report")
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		j.file.Close()
+		return errors.Wrap(err, "could not write junit report")
+	}
+	return j.file.Close()
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+func TestJUnitWriter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	reportFile := filepath.Join(t.TempDir(), "report.xml")
+	junitOutput, err := newJUnitWriter(reportFile)
+	require.NoError(t, err)
+
+	w := newTestWriter(ts.URL)
+	w.junitOutput = junitOutput
+
+	events := []*ResultEvent{
+		{TemplateID: "git-config", Host: "https://a.example.com", Matched: "https://a.example.com/.git/config", Info: model.Info{Name: "Git Config", SeverityHolder: severity.Holder{Severity: severity.Medium}}},
+		{TemplateID: "cve-2021-44228", Host: "https://a.example.com", Matched: "https://a.example.com/login", ExtractedResults: []string{"jndi"}, Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Critical}}},
+		{TemplateID: "git-config", Host: "https://b.example.com", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Medium}}},
+	}
+	for _, event := range events {
+		require.NoError(t, w.Write(event))
+	}
+	require.NoError(t, junitOutput.Close())
+
+	data, err := os.ReadFile(reportFile)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), xml.Header), "missing xml header")
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+	require.Equal(t, "nuclei", report.Name)
+	require.Equal(t, 3, report.Tests)
+	require.Equal(t, 3, report.Failures)
+	require.Len(t, report.Suites, 2)
+
+	cve := report.Suites[0]
+	require.Equal(t, "cve-2021-44228", cve.Name)
+	require.Equal(t, 1, cve.Tests)
+	require.Equal(t, 1, cve.Failures)
+	require.Equal(t, "https://a.example.com/login", cve.Cases[0].Name)
+	require.Equal(t, "[critical] https://a.example.com/login", cve.Cases[0].Failure.Message)
+	require.Equal(t, "critical", cve.Cases[0].Failure.Type)
+	require.Contains(t, cve.Cases[0].Failure.Text, "extracted-results: jndi")
+
+	gitConfig := report.Suites[1]
+	require.Equal(t, "git-config", gitConfig.Name)
+	require.Equal(t, 2, gitConfig.Tests)
+	require.Equal(t, 2, gitConfig.Failures)
+	require.Len(t, gitConfig.Cases, 2)
+	require.Equal(t, "git-config", gitConfig.Cases[0].ClassName)
+	require.Equal(t, "https://a.example.com/.git/config", gitConfig.Cases[0].Name)
+	require.Contains(t, gitConfig.Cases[0].Failure.Text, "name: Git Config")
+	require.Equal(t, "https://b.example.com", gitConfig.Cases[1].Name, "host should be used without matched-at")
+	require.Equal(t, "[medium] https://b.example.com", gitConfig.Cases[1].Failure.Message)
+}
+
+func TestJUnitWriterEmpty(t *testing.T) {
+	reportFile := filepath.Join(t.TempDir(), "report.xml")
+	junitOutput, err := newJUnitWriter(reportFile)
+	require.NoError(t, err)
+	require.NoError(t, junitOutput.Close())
+
+	data, err := os.ReadFile(reportFile)
+	require.NoError(t, err)
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+	require.Equal(t, 0, report.Tests)
+	require.Empty(t, report.Suites)
+
+	_, err = newJUnitWriter(filepath.Join(t.TempDir(), "missing", "report.xml"))
+	require.ErrorContains(t, err, "could not create junit report")
+}
@@ -0,0 +1,35 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Line endings of the stored and reconstructed responses
+const (
+	// LineEndingsLF ends the lines with \n
+	LineEndingsLF = "lf"
+	// LineEndingsCRLF ends the lines with \r\n
+	LineEndingsCRLF = "crlf"
+)
+
+// validateLineEndings validates the line endings of the responses
+func validateLineEndings(lineEndings string) error {
+	switch lineEndings {
+	case "", LineEndingsLF, LineEndingsCRLF:
+		return nil
+	}
+	return fmt.Errorf("invalid line endings %q: expected %s or %s", lineEndings, LineEndingsLF, LineEndingsCRLF)
+}
+
+// normalizeLineEndings returns the text with its \r\n and \n line endings
+// replaced by the line endings, unchanged if they aren't set.
+func normalizeLineEndings(text, lineEndings string) string {
+	switch lineEndings {
+	case LineEndingsLF:
+		return strings.ReplaceAll(text, "\r\n", "\n")
+	case LineEndingsCRLF:
+		return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	}
+	return text
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestNormalizeLineEndings(t *testing.T) {
+	mixed := "GET / HTTP/1.1\r\nHost: example.com\n\r\nbody\nend"
+	require.Equal(t, mixed, normalizeLineEndings(mixed, ""))
+	require.Equal(t, "GET / HTTP/1.1\nHost: example.com\n\nbody\nend", normalizeLineEndings(mixed, LineEndingsLF))
+	require.Equal(t, "GET / HTTP/1.1\r\nHost: example.com\r\n\r\nbody\r\nend", normalizeLineEndings(mixed, LineEndingsCRLF))
+
+	t.Run("Invalid", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{ResponseLineEndings: "cr"})
+		require.ErrorContains(t, err, `invalid line endings "cr"`)
+	})
+}
+
+func TestStandardWriterLineEndings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	mixed := "HTTP/1.1 200 OK\r\nServer: test\r\n\r\n<html>\r\n</html>\n"
+	tests := []struct {
+		lineEndings   string
+		stored        string
+		reconstructed string
+	}{
+		{LineEndingsLF, "HTTP/1.1 200 OK\nServer: test\n\n<html>\n</html>\n\n", "HTTP/1.1 200 OK\nserver: test\n\n<html>\n</html>\n"},
+		{LineEndingsCRLF, "HTTP/1.1 200 OK\r\nServer: test\r\n\r\n<html>\r\n</html>\r\n\r\n", "HTTP/1.1 200 OK\r\nserver: test\r\n\r\n<html>\r\n</html>\r\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.lineEndings, func(t *testing.T) {
+			outputFile := &testWriteCloser{}
+			w := newTestWriter(ts.URL)
+			w.outputFile = outputFile
+			w.jsonReqResp = true
+			w.statusLine = true
+			w.responseBody = true
+			w.storeResponse = true
+			w.storeResponseDir = t.TempDir()
+			w.lineEndings = test.lineEndings
+
+			w.WriteStoreDebugData("https://example.com", "git-config", "http", mixed)
+			stored, err := os.ReadFile(filepath.Join(w.storeResponseDir, "http", "example_com_git_config.txt"))
+			require.NoError(t, err)
+			require.Equal(t, test.stored, string(stored))
+
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Response: mixed}))
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+			reconstructed, err := base64.StdEncoding.DecodeString(fields["response"].(string))
+			require.NoError(t, err)
+			require.Equal(t, test.reconstructed, string(reconstructed))
+		})
+	}
+}
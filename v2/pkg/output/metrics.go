@@ -0,0 +1,95 @@
+package output
+
+import (
+	"sync/atomic"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+// Metrics is a snapshot of the counters of a writer
+type Metrics struct {
+	// AlertsSent is the number of alerts accepted by the webhook
+	AlertsSent uint64 `json:"alerts-sent"`
+	// BackupAlertsSent is the number of alerts accepted by the backup webhook
+	BackupAlertsSent uint64 `json:"backup-alerts-sent"`
+	// WebhookFailures is the number of webhook deliveries which failed after all retries
+	WebhookFailures uint64 `json:"webhook-failures"`
+	// WebhookRetries is the number of retried webhook delivery attempts
+	WebhookRetries uint64 `json:"webhook-retries"`
+	// AlertsDropped is the number of alerts dropped by the alert queue
+	AlertsDropped uint64 `json:"alerts-dropped"`
+	// StatusChanges is the number of status changes sent to the api service
+	StatusChanges uint64 `json:"status-changes"`
+	// Panics is the number of panics recovered while writing results
+	Panics uint64 `json:"panics"`
+	// AlertsOverQuota is the number of alerts not sent as the alert quota was reached
+	AlertsOverQuota uint64 `json:"alerts-over-quota"`
+	// PublishFailures is the number of alerts the alert transport failed to publish
+	PublishFailures uint64 `json:"publish-failures"`
+}
+
+// writerMetrics holds the counters of a writer updated on the hot path
+type writerMetrics struct {
+	alertsSent       atomic.Uint64
+	backupAlertsSent atomic.Uint64
+	webhookFailures  atomic.Uint64
+	webhookRetries   atomic.Uint64
+	statusChanges    atomic.Uint64
+	panics           atomic.Uint64
+	alertsOverQuota  atomic.Uint64
+	publishFailures  atomic.Uint64
+	// severities is the number of results written per severity
+	severities [severity.Unknown + 1]atomic.Uint64
+}
+
+// countSeverity counts a result of the severity
+func (m *writerMetrics) countSeverity(value severity.Severity) {
+	if value >= severity.Undefined && value <= severity.Unknown {
+		m.severities[value].Add(1)
+	}
+}
+
+// Metrics returns a snapshot of the writer counters
+func (w *StandardWriter) Metrics() Metrics {
+	metrics := Metrics{
+		AlertsSent:       w.metrics.alertsSent.Load(),
+		BackupAlertsSent: w.metrics.backupAlertsSent.Load(),
+		WebhookFailures:  w.metrics.webhookFailures.Load(),
+		WebhookRetries:   w.metrics.webhookRetries.Load(),
+		StatusChanges:    w.metrics.statusChanges.Load(),
+		Panics:           w.metrics.panics.Load(),
+		AlertsOverQuota:  w.metrics.alertsOverQuota.Load(),
+		PublishFailures:  w.metrics.publishFailures.Load(),
+	}
+	if w.alertQueue != nil {
+		metrics.AlertsDropped = w.alertQueue.Dropped()
+	}
+	return metrics
+}
+
+// Counts returns the number of results written so far per severity.
+// It is safe to call concurrently with Write, severities without results
+// are omitted.
+func (w *StandardWriter) Counts() map[severity.Severity]int {
+	counts := make(map[severity.Severity]int)
+	for value := range w.metrics.severities {
+		if count := w.metrics.severities[value].Load(); count > 0 {
+			counts[severity.Severity(value)] = int(count)
+		}
+	}
+	return counts
+}
+
+// HasSeverityAtLeast returns true if a result of the severity or of
+// a more severe one was written, eg. to fail fast on a critical result.
+func (w *StandardWriter) HasSeverityAtLeast(minimum severity.Severity) bool {
+	if minimum < severity.Undefined {
+		minimum = severity.Undefined
+	}
+	for value := minimum; value <= severity.Critical; value++ {
+		if w.metrics.severities[value].Load() > 0 {
+			return true
+		}
+	}
+	return false
+}
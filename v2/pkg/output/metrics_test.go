@@ -0,0 +1,118 @@
+package output
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestStandardWriterMetrics(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		w := newTestWriter(ts.URL)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		}
+		require.Equal(t, Metrics{AlertsSent: 3}, w.Metrics())
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		w := newTestWriter(ts.URL)
+		w.webhookRetries = 2
+		w.webhookRetryDelay = time.Millisecond
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		require.Equal(t, Metrics{WebhookFailures: 1, WebhookRetries: 2}, w.Metrics())
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		w := newTestWriter(ts.URL)
+		w.webhookRetries = 2
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		require.Equal(t, Metrics{WebhookFailures: 1}, w.Metrics())
+	})
+
+	t.Run("Dropped", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+		}))
+		defer ts.Close()
+
+		w := newTestWriter(ts.URL)
+		w.alertQueue = newAlertQueue(1, 1, DropPolicyDropNewest, w.deliverAlert)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		<-started
+		for i := 0; i < 3; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		}
+		close(release)
+		require.True(t, w.alertQueue.close(time.Second))
+		require.Equal(t, Metrics{AlertsSent: 2, AlertsDropped: 2}, w.Metrics())
+	})
+
+	t.Run("StatusChanges", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+		w, err := NewStandardWriter(&types.Options{})
+		require.NoError(t, err)
+		w.Start()
+		require.Equal(t, uint64(1), w.Metrics().StatusChanges)
+		w.Close()
+		require.Equal(t, uint64(2), w.Metrics().StatusChanges)
+	})
+}
+
+func TestStandardWriterCounts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	require.Empty(t, w.Counts())
+	require.False(t, w.HasSeverityAtLeast(severity.Info))
+
+	severities := []severity.Severity{severity.Info, severity.Medium, severity.Info, severity.High, severity.Medium, severity.Info}
+	var wg sync.WaitGroup
+	for _, value := range severities {
+		wg.Add(1)
+		go func(value severity.Severity) {
+			defer wg.Done()
+			_ = w.Write(&ResultEvent{TemplateID: "test", Info: model.Info{SeverityHolder: severity.Holder{Severity: value}}})
+		}(value)
+	}
+	wg.Wait()
+
+	require.Equal(t, map[severity.Severity]int{severity.Info: 3, severity.Medium: 2, severity.High: 1}, w.Counts())
+	require.True(t, w.HasSeverityAtLeast(severity.Low))
+	require.True(t, w.HasSeverityAtLeast(severity.High))
+	require.False(t, w.HasSeverityAtLeast(severity.Critical))
+
+	w.severityOverrides = map[string]severity.Severity{"overridden": severity.Critical}
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "overridden", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Low}}}))
+	require.True(t, w.HasSeverityAtLeast(severity.Critical), "overridden severity should be counted")
+	require.Equal(t, 0, w.Counts()[severity.Low])
+}
@@ -0,0 +1,136 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Formats of the webhook alert payloads
+const (
+	// AlertFormatJSON sends the payloads as json
+	AlertFormatJSON = "json"
+	// AlertFormatMsgpack sends the payloads as MessagePack
+	AlertFormatMsgpack = "msgpack"
+)
+
+// validateAlertFormat validates the format of the webhook alert payloads
+func validateAlertFormat(format string) error {
+	switch format {
+	case "", AlertFormatJSON, AlertFormatMsgpack:
+		return nil
+	}
+	return fmt.Errorf("invalid alert format %q: expected %s or %s", format, AlertFormatJSON, AlertFormatMsgpack)
+}
+
+// alertContentType returns the content type of the payloads in the format
+func alertContentType(format string) string {
+	if format == AlertFormatMsgpack {
+		return "application/msgpack"
+	}
+	return "application/json"
+}
+
+// jsonToMsgpack converts the json payload to MessagePack. Objects are
+// written with their keys sorted so the output is deterministic.
+func jsonToMsgpack(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := decodeJSONNumbers(data, &value); err != nil {
+		return nil, errors.Wrap(err, "could not convert payload to msgpack")
+	}
+	buffer := &bytes.Buffer{}
+	if err := writeMsgpack(buffer, value); err != nil {
+		return nil, errors.Wrap(err, "could not convert payload to msgpack")
+	}
+	return buffer.Bytes(), nil
+}
+
+// writeMsgpack writes the decoded json value as MessagePack
+func writeMsgpack(buffer *bytes.Buffer, value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		buffer.WriteByte(0xc0)
+	case bool:
+		if value {
+			buffer.WriteByte(0xc3)
+		} else {
+			buffer.WriteByte(0xc2)
+		}
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			writeMsgpackInt(buffer, integer)
+			return nil
+		}
+		float, err := value.Float64()
+		if err != nil {
+			return err
+		}
+		buffer.WriteByte(0xcb)
+		_ = binary.Write(buffer, binary.BigEndian, math.Float64bits(float))
+	case string:
+		writeMsgpackHeader(buffer, len(value), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buffer.WriteString(value)
+	case []interface{}:
+		writeMsgpackHeader(buffer, len(value), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range value {
+			if err := writeMsgpack(buffer, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		writeMsgpackHeader(buffer, len(value), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			if err := writeMsgpack(buffer, key); err != nil {
+				return err
+			}
+			if err := writeMsgpack(buffer, value[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported msgpack value %T", value)
+	}
+	return nil
+}
+
+// writeMsgpackInt writes the integer with the smallest fixint or the int64 format
+func writeMsgpackInt(buffer *bytes.Buffer, value int64) {
+	switch {
+	case value >= 0 && value <= 0x7f:
+		buffer.WriteByte(byte(value))
+	case value >= -32 && value < 0:
+		buffer.WriteByte(byte(int8(value)))
+	default:
+		buffer.WriteByte(0xd3)
+		_ = binary.Write(buffer, binary.BigEndian, value)
+	}
+}
+
+// writeMsgpackHeader writes the header of a string, array or map of the
+// length with the fix format if shorter than fixLimit, else the 8, 16 or
+// 32 bits one. Arrays and maps have no 8 bits format.
+func writeMsgpackHeader(buffer *bytes.Buffer, length int, fix byte, fixLimit int, format8, format16, format32 byte) {
+	switch {
+	case length < fixLimit:
+		buffer.WriteByte(fix | byte(length))
+	case format8 != 0 && length <= math.MaxUint8:
+		buffer.WriteByte(format8)
+		buffer.WriteByte(byte(length))
+	case length <= math.MaxUint16:
+		buffer.WriteByte(format16)
+		_ = binary.Write(buffer, binary.BigEndian, uint16(length))
+	default:
+		buffer.WriteByte(format32)
+		_ = binary.Write(buffer, binary.BigEndian, uint32(length))
+	}
+}
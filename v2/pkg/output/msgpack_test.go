@@ -0,0 +1,190 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestWebhookMsgpack(t *testing.T) {
+	var contentType string
+	var payload []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		payload, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	w.alertFormat = AlertFormatMsgpack
+	w.AstraMeta = AstraMeta{ScanId: "scan-id"}
+	event := &ResultEvent{
+		TemplateID:       "git-config",
+		Host:             "https://example.com",
+		Matched:          "https://example.com/.git/config",
+		ExtractedResults: []string{"[core]", strings.Repeat("a", 300)},
+		Info:             model.Info{Name: "Git Config", SeverityHolder: severity.Holder{Severity: severity.Medium}},
+		Lines:            []int{1, 70000},
+		CVSSScore:        7.5,
+		Latency:          -5,
+	}
+	require.NoError(t, w.Write(event))
+	require.Equal(t, "application/msgpack", contentType)
+	require.False(t, json.Valid(payload), "payload was sent as json")
+
+	value, err := readMsgpack(bytes.NewReader(payload))
+	require.NoError(t, err)
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	var received struct {
+		Meta    AstraMeta   `json:"meta"`
+		Context ResultEvent `json:"context"`
+	}
+	require.NoError(t, json.Unmarshal(data, &received))
+	require.Equal(t, "alert", received.Meta.Event)
+	require.Equal(t, "scan-id", received.Meta.ScanId)
+	require.Equal(t, event.TemplateID, received.Context.TemplateID)
+	require.Equal(t, event.Matched, received.Context.Matched)
+	require.Equal(t, event.ExtractedResults, received.Context.ExtractedResults)
+	require.Equal(t, event.Info.Name, received.Context.Info.Name)
+	require.Equal(t, severity.Medium, received.Context.Info.SeverityHolder.Severity)
+	require.Equal(t, event.Lines, received.Context.Lines)
+	require.Equal(t, event.CVSSScore, received.Context.CVSSScore)
+	require.Equal(t, event.Latency, received.Context.Latency)
+	require.Equal(t, event.EventID, received.Context.EventID)
+
+	t.Run("JSONDefault", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.Equal(t, "application/json", contentType)
+		require.True(t, json.Valid(payload))
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{AlertFormat: "xml"})
+		require.ErrorContains(t, err, `invalid alert format "xml"`)
+	})
+}
+
+// readMsgpack reads a MessagePack value of the subset written by writeMsgpack
+func readMsgpack(reader *bytes.Reader) (interface{}, error) {
+	format, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case format <= 0x7f:
+		return int64(format), nil
+	case format >= 0xe0:
+		return int64(int8(format)), nil
+	case format&0xe0 == 0xa0:
+		return readMsgpackString(reader, int(format&0x1f))
+	case format&0xf0 == 0x90:
+		return readMsgpackArray(reader, int(format&0x0f))
+	case format&0xf0 == 0x80:
+		return readMsgpackMap(reader, int(format&0x0f))
+	}
+	switch format {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return format == 0xc3, nil
+	case 0xd3:
+		var value int64
+		err := binary.Read(reader, binary.BigEndian, &value)
+		return value, err
+	case 0xcb:
+		var bits uint64
+		err := binary.Read(reader, binary.BigEndian, &bits)
+		return math.Float64frombits(bits), err
+	case 0xd9, 0xda, 0xdb:
+		length, err := readMsgpackLength(reader, format-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(reader, length)
+	case 0xdc, 0xdd:
+		length, err := readMsgpackLength(reader, format-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(reader, length)
+	case 0xde, 0xdf:
+		length, err := readMsgpackLength(reader, format-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(reader, length)
+	}
+	return nil, fmt.Errorf("unsupported msgpack format 0x%x", format)
+}
+
+// readMsgpackLength reads a length of 8, 16 or 32 bits for size 0, 1 or 2
+func readMsgpackLength(reader *bytes.Reader, size byte) (int, error) {
+	switch size {
+	case 0:
+		length, err := reader.ReadByte()
+		return int(length), err
+	case 1:
+		var length uint16
+		err := binary.Read(reader, binary.BigEndian, &length)
+		return int(length), err
+	default:
+		var length uint32
+		err := binary.Read(reader, binary.BigEndian, &length)
+		return int(length), err
+	}
+}
+
+func readMsgpackString(reader *bytes.Reader, length int) (interface{}, error) {
+	data := make([]byte, length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func readMsgpackArray(reader *bytes.Reader, length int) (interface{}, error) {
+	values := make([]interface{}, 0, length)
+	for i := 0; i < length; i++ {
+		value, err := readMsgpack(reader)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func readMsgpackMap(reader *bytes.Reader, length int) (interface{}, error) {
+	values := make(map[string]interface{}, length)
+	for i := 0; i < length; i++ {
+		key, err := readMsgpack(reader)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("unsupported msgpack map key %T", key)
+		}
+		if values[name], err = readMsgpack(reader); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
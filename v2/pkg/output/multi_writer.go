@@ -0,0 +1,94 @@
+package output
+
+import (
+	"github.com/logrusorgru/aurora"
+	"go.uber.org/multierr"
+)
+
+// MultiWriter is a writer which fans out nuclei events to multiple writers.
+//
+// It can be used to compose writers, for instance a local file writer
+// with a webhook writer.
+type MultiWriter struct {
+	writers []Writer
+}
+
+var _ Writer = &MultiWriter{}
+
+// NewMultiWriter creates a new writer writing events to all of the provided writers.
+func NewMultiWriter(writers ...Writer) *MultiWriter {
+	return &MultiWriter{writers: writers}
+}
+
+// Close closes all the underlying writers
+func (m *MultiWriter) Close() {
+	for _, writer := range m.writers {
+		writer.Close()
+	}
+}
+
+// Flush flushes all the underlying writers
+func (m *MultiWriter) Flush() error {
+	var err error
+	for _, writer := range m.writers {
+		if flushErr := writer.Flush(); flushErr != nil {
+			err = multierr.Append(err, flushErr)
+		}
+	}
+	return err
+}
+
+// Colorizer returns the colorizer instance of the first writer
+func (m *MultiWriter) Colorizer() aurora.Aurora {
+	if len(m.writers) == 0 {
+		return aurora.NewAurora(false)
+	}
+	return m.writers[0].Colorizer()
+}
+
+// Write writes the event to all the underlying writers.
+//
+// Writers are free to enrich the event in place, so each of them
+// receives its own copy of the event.
+func (m *MultiWriter) Write(event *ResultEvent) error {
+	var err error
+	for _, writer := range m.writers {
+		eventCopy := *event
+		if writeErr := writer.Write(&eventCopy); writeErr != nil {
+			err = multierr.Append(err, writeErr)
+		}
+	}
+	return err
+}
+
+// WriteFailure writes the failure event for template to all the underlying writers.
+func (m *MultiWriter) WriteFailure(event InternalEvent) error {
+	var err error
+	for _, writer := range m.writers {
+		if writeErr := writer.WriteFailure(event); writeErr != nil {
+			err = multierr.Append(err, writeErr)
+		}
+	}
+	return err
+}
+
+// Request logs a request in the trace log of all the underlying writers
+func (m *MultiWriter) Request(templateID, url, requestType string, err error) {
+	for _, writer := range m.writers {
+		writer.Request(templateID, url, requestType, err)
+	}
+}
+
+// WriteError writes an error to the error log of all the underlying writers
+func (m *MultiWriter) WriteError(templateID, input string, err error) {
+	for _, writer := range m.writers {
+		writer.WriteError(templateID, input, err)
+	}
+}
+
+// WriteStoreDebugData writes the request/response debug data to all the underlying writers
+func (m *MultiWriter) WriteStoreDebugData(host, templateID, eventType string, data string) {
+	for _, writer := range m.writers {
+		writer.WriteStoreDebugData(host, templateID, eventType, data)
+	}
+}
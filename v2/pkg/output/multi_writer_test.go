@@ -0,0 +1,84 @@
+package output
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiWriter(t *testing.T) {
+	first := &mockWriter{}
+	second := &mockWriter{}
+	writer := NewMultiWriter(first, second)
+
+	require.NoError(t, writer.Write(&ResultEvent{TemplateID: "test"}))
+	require.NoError(t, writer.WriteFailure(InternalEvent{"template-id": "test"}))
+	writer.Request("test", "https://example.com", "http", nil)
+	writer.WriteError("test", "https://example.com", errors.New("could not connect"))
+	writer.WriteStoreDebugData("example.com", "test", "http", "data")
+	require.NoError(t, writer.Flush())
+	writer.Close()
+
+	for _, mock := range []*mockWriter{first, second} {
+		require.Len(t, mock.events, 1)
+		require.Equal(t, "test", mock.events[0].TemplateID)
+		require.Len(t, mock.failures, 1)
+		require.Equal(t, 1, mock.requests)
+		require.Equal(t, 1, mock.errors)
+		require.Equal(t, 1, mock.debugData)
+		require.Equal(t, 1, mock.flushes)
+		require.True(t, mock.closed)
+	}
+
+	t.Run("AggregatedErrors", func(t *testing.T) {
+		writer := NewMultiWriter(
+			&mockWriter{writeErr: errors.New("first failed")},
+			&mockWriter{},
+			&mockWriter{writeErr: errors.New("third failed")},
+		)
+		err := writer.Write(&ResultEvent{TemplateID: "test"})
+		require.ErrorContains(t, err, "first failed")
+		require.ErrorContains(t, err, "third failed")
+	})
+}
+
+// mockWriter is a writer recording the calls made to it
+type mockWriter struct {
+	writeErr  error
+	events    []*ResultEvent
+	failures  []InternalEvent
+	requests  int
+	errors    int
+	debugData int
+	flushes   int
+	closed    bool
+}
+
+func (m *mockWriter) Close() { m.closed = true }
+
+func (m *mockWriter) Flush() error {
+	m.flushes++
+	return nil
+}
+
+func (m *mockWriter) Colorizer() aurora.Aurora { return aurora.NewAurora(false) }
+
+func (m *mockWriter) Write(event *ResultEvent) error {
+	m.events = append(m.events, event)
+	return m.writeErr
+}
+
+func (m *mockWriter) WriteFailure(event InternalEvent) error {
+	m.failures = append(m.failures, event)
+	return m.writeErr
+}
+
+func (m *mockWriter) Request(templateID, url, requestType string, err error) { m.requests++ }
+
+func (m *mockWriter) WriteError(templateID, input string, err error) { m.errors++ }
+
+func (m *mockWriter) WriteStoreDebugData(host, templateID, eventType string, data string) {
+	m.debugData++
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+)
+
+// normalizeMetadataNumbers returns the metadata with the integral float
+// values, eg. status codes and lengths evaluated as floats by the dsl,
+// replaced by integers so they are written without a fraction or an
+// exponent. The metadata is copied if any value is replaced.
+func normalizeMetadataNumbers(metadata map[string]interface{}) map[string]interface{} {
+	normalized, _ := normalizeMapNumbers(metadata)
+	return normalized
+}
+
+// normalizeNumber returns the value with its integral floats replaced by
+// integers, recursing into slices and maps which are copied if needed.
+// It returns false if nothing was replaced.
+func normalizeNumber(value interface{}) (interface{}, bool) {
+	switch value := value.(type) {
+	case float64:
+		return floatToInt(value)
+	case float32:
+		return floatToInt(float64(value))
+	case []interface{}:
+		var normalized []interface{}
+		for i, item := range value {
+			number, ok := normalizeNumber(item)
+			if !ok {
+				continue
+			}
+			if normalized == nil {
+				normalized = append([]interface{}{}, value...)
+			}
+			normalized[i] = number
+		}
+		if normalized == nil {
+			return value, false
+		}
+		return normalized, true
+	case map[string]interface{}:
+		return normalizeMapNumbers(value)
+	}
+	return value, false
+}
+
+// normalizeMapNumbers normalizes the numbers of the map values, see normalizeNumber
+func normalizeMapNumbers(values map[string]interface{}) (map[string]interface{}, bool) {
+	var normalized map[string]interface{}
+	for key, value := range values {
+		number, ok := normalizeNumber(value)
+		if !ok {
+			continue
+		}
+		if normalized == nil {
+			normalized = make(map[string]interface{}, len(values))
+			for key, value := range values {
+				normalized[key] = value
+			}
+		}
+		normalized[key] = number
+	}
+	if normalized == nil {
+		return values, false
+	}
+	return normalized, true
+}
+
+// floatToInt returns the float as an int64 if it is integral and in range
+func floatToInt(value float64) (interface{}, bool) {
+	if value != math.Trunc(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+		return value, false
+	}
+	return int64(value), true
+}
+
+// decodeJSONNumbers decodes the json data into value keeping the numbers
+// as json.Number so large integers don't lose precision as floats.
+func decodeJSONNumbers(data []byte, value interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
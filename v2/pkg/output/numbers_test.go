@@ -0,0 +1,70 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardWriterNumbers(t *testing.T) {
+	outputFile := &testWriteCloser{}
+	w := newTestWriter("")
+	w.outputFile = outputFile
+	metadata := map[string]interface{}{
+		"status_code":    float64(200),
+		"content_length": float64(1 << 53),
+		"request_id":     json.Number("12345678901234567890"),
+		"ratio":          0.5,
+		"redirects":      []interface{}{float64(301), float64(1e15)},
+		"timing":         map[string]interface{}{"total": float32(1500)},
+	}
+	event := &ResultEvent{
+		TemplateID: "numbers",
+		Lines:      []int{1, 1 << 40},
+		Latency:    1 << 50,
+		Metadata:   metadata,
+	}
+	require.NoError(t, w.Write(event))
+	payload := []byte(outputFile.String())
+
+	for _, expected := range []string{
+		`"status_code":200`,
+		`"content_length":9007199254740992`,
+		`"request_id":12345678901234567890`,
+		`"ratio":0.5`,
+		`"redirects":[301,1000000000000000]`,
+		`"timing":{"total":1500}`,
+		`"matched-line":[1,1099511627776]`,
+		`"latency-ms":1125899906842624`,
+	} {
+		require.Contains(t, string(payload), expected)
+	}
+	require.NotContains(t, string(payload), "e+", "numbers were written in scientific notation")
+	require.Equal(t, float64(200), metadata["status_code"], "the metadata of the caller was modified")
+
+	var decoded struct {
+		Meta map[string]interface{} `json:"meta"`
+	}
+	require.NoError(t, decodeJSONNumbers(payload, &decoded))
+	require.Equal(t, json.Number("12345678901234567890"), decoded.Meta["request_id"], "large integer lost precision")
+	require.Equal(t, json.Number("9007199254740992"), decoded.Meta["content_length"])
+
+	t.Run("Normalize", func(t *testing.T) {
+		tests := []struct {
+			value    interface{}
+			expected interface{}
+		}{
+			{float64(404), int64(404)},
+			{float64(-1), int64(-1)},
+			{1.5, 1.5},
+			{float64(1e21), float64(1e21)},
+			{"200", "200"},
+			{json.Number("200"), json.Number("200")},
+		}
+		for _, test := range tests {
+			value, _ := normalizeNumber(test.value)
+			require.Equal(t, test.expected, value)
+		}
+	})
+}
@@ -1,24 +1,32 @@
 package output
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	b64 "encoding/base64"
 
 	"github.com/pkg/errors"
 
-	jsoniter "github.com/json-iterator/go"
 	"github.com/logrusorgru/aurora"
 
 	"github.com/projectdiscovery/gologger"
@@ -31,12 +39,16 @@ import (
 	"github.com/projectdiscovery/nuclei/v2/pkg/utils"
 	fileutil "github.com/projectdiscovery/utils/file"
 	osutils "github.com/projectdiscovery/utils/os"
+	"go.uber.org/multierr"
+	"golang.org/x/time/rate"
 )
 
 // Writer is an interface which writes output to somewhere for nuclei events.
 type Writer interface {
 	// Close closes the output writer interface
 	Close()
+	// Flush writes out any buffered output and pending alerts
+	Flush() error
 	// Colorizer returns the colorizer instance for writer
 	Colorizer() aurora.Aurora
 	// Write writes the event to file and/or screen.
@@ -45,6 +57,8 @@ type Writer interface {
 	WriteFailure(event InternalEvent) error
 	// Request logs a request in the trace log
 	Request(templateID, url, requestType string, err error)
+	// WriteError writes an error encountered for a template and input to the error log
+	WriteError(templateID, input string, err error)
 	//  WriteStoreDebugData writes the request/response debug data to file
 	WriteStoreDebugData(host, templateID, eventType string, data string)
 }
@@ -52,24 +66,136 @@ type Writer interface {
 // StandardWriter is a writer writing output to file and screen for results.
 type StandardWriter struct {
 	json                bool
+	outputFormat        string
 	jsonReqResp         bool
 	timestamp           bool
 	noMetadata          bool
 	matcherStatus       bool
+	compactOutput       bool
+	prettyJSON          bool
+	screenBodyLimit     int
 	AstraMeta           AstraMeta
 	AstraWebhook        string
+	backupWebhook       string
+	severityWebhooks    map[severity.Severity]string
+	excludedSeverities  map[severity.Severity]struct{}
+	excludeFromOutput   bool
+	maxAlerts           int64
+	alertCount          atomic.Int64
+	quotaOnce           sync.Once
+	quotaEvent          sync.WaitGroup
+	quotaSkipOutput     bool
+	extractedValues     bool
 	AstraApiServiceName string
 	mutex               *sync.Mutex
 	aurora              aurora.Aurora
 	outputFile          io.WriteCloser
+	jsonOutputFile      io.WriteCloser
+	textOutputFile      io.WriteCloser
+	stdout              io.Writer
 	traceFile           io.WriteCloser
 	errorFile           io.WriteCloser
+	sqliteOutput        *sqliteWriter
+	junitOutput         *junitWriter
+	cyclonedxReport     *cyclonedxReport
+	syslogOutput        *syslogWriter
+	maxAlertBytes       int
+	verbose             bool
+	silent              bool
+	rawResponse         bool
+	authHeader          string
+	authScheme          string
+	authToken           string
+	responseBody        bool
+	alertBatcher        *alertBatcher
+	noReconstruction    bool
+	noWebhookRecon      bool
+	statusLine          bool
+	lineEndings         string
+	fieldLimits         map[string]int
+	traceFormat         string
 	severityColors      func(severity.Severity) string
 	storeResponse       bool
 	storeResponseDir    string
+	storeDateFolders    bool
+	storedFolders       sync.Map
+	createFolder        func(path string) error
+	now                 func() time.Time
+	webhookErrors       bool
+	webhookRetries      int
+	webhookRetryDelay   time.Duration
+	retryAfterUnit      time.Duration
+	httpClient          *http.Client
+	statusClient        *http.Client
+	statusScheme        string
+	alertQueue          *alertQueue
+	drainTimeout        time.Duration
+	webhookSecret       string
+	spool               *alertSpool
+	statusMethod        string
+	statusPath          string
+	gzipThreshold       int
+	alertFormat         string
+	envelopeVersion     string
+	alertTransport      AlertTransport
+	attachmentStore     AttachmentStore
+	normalizeMatchedAt  bool
+	storedFilesMutex    sync.Mutex
+	storedFiles         map[string]struct{}
+	storedCounts        map[string]int
+	maxStoredResponses  int
+	maxOpenStored       int
+	storedPool          *storedFilePool
+	storedPoolOnce      sync.Once
+	webhookTemplate     *template.Template
+	metrics             writerMetrics
+	templateCounts      templateCounter
+	resume              bool
+	startOnce           sync.Once
+	requireStatusAPI    bool
+	statusRunning       bool
+	closeOnce           sync.Once
+	tolerateOutputErr   bool
+	outputErrOnce       sync.Once
+	scanMutex           sync.Mutex
+	scanTargets         int
+	scanTemplates       int
+	scanStartTime       time.Time
+	severityOverrides   map[string]severity.Severity
+	fieldFilter         *fieldFilter
+	sequence            uint64
+	templateFilter      *templateFilter
+	hostFilter          *hostFilter
+	fingerprintFields   []string
+	templateSource      bool
+	templateSourceLimit int
+	templateSources     sync.Map
+	enrichIP            bool
+	resolver            hostResolver
+	resolvedIPs         sync.Map
+	deliveryCtx         context.Context
+	cancelDeliveries    context.CancelFunc
+
+	// OnResult is an optional callback invoked synchronously with each
+	// enriched result before it is delivered to the webhook.
+	OnResult func(*ResultEvent)
+	// StoredFileNameFunc optionally names the files responses are stored
+	// in, inside the folder of their event type. DefaultStoredFileName is
+	// used when not set.
+	StoredFileNameFunc func(host, templateID, eventType string) string
 }
 
-var decolorizerRegex = regexp.MustCompile(`\x1B\[[0-9;]*[a-zA-Z]`)
+// ResultSchemaVersion is the version of the structure of the results written
+// by the writer. It is bumped when fields of the result are changed or removed.
+const ResultSchemaVersion = "1.0.0"
+
+// decolorizerRegex matches ANSI escape sequences: CSI sequences (colors, cursor
+// movement), OSC sequences terminated by BEL or ST (titles, hyperlinks) and
+// single character escapes.
+var decolorizerRegex = regexp.MustCompile(`\x1B(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1B]*(?:\x07|\x1B\\)|[0-~])`)
+
+// envPlaceholderRegex matches ${VAR} environment variable placeholders
+var envPlaceholderRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
 
 // InternalEvent is an internal output generation structure for nuclei.
 type InternalEvent map[string]interface{}
@@ -114,6 +240,16 @@ type ResultEvent struct {
 	TemplatePath string `json:"template-path,omitempty"`
 	// Info contains information block of the template for the result.
 	Info model.Info `json:"info,inline"`
+	// CVE contains the CVE IDs of the template classification if any.
+	CVE []string `json:"cve,omitempty"`
+	// CWE contains the CWE IDs of the template classification if any.
+	CWE []string `json:"cwe,omitempty"`
+	// CVSSScore is the CVSS score of the template classification if any.
+	CVSSScore float64 `json:"cvss-score,omitempty"`
+	// Authors contains the authors of the template if any.
+	Authors []string `json:"authors,omitempty"`
+	// Tags contains the tags of the template if any.
+	Tags []string `json:"tags,omitempty"`
 	// MatcherName is the name of the matcher matched if any.
 	MatcherName string `json:"matcher-name,omitempty"`
 	// ExtractorName is the name of the extractor matched if any.
@@ -126,12 +262,25 @@ type ResultEvent struct {
 	Path string `json:"path,omitempty"`
 	// Matched contains the matched input in its transformed form.
 	Matched string `json:"matched-at,omitempty"`
+	// MatchedScheme, MatchedPort and MatchedPath are parsed from the
+	// matched-at url of http results, the port defaulting to the one
+	// of the scheme.
+	MatchedScheme string `json:"matched-scheme,omitempty"`
+	MatchedPort   int    `json:"matched-port,omitempty"`
+	MatchedPath   string `json:"matched-path,omitempty"`
 	// ExtractedResults contains the extraction result from the inputs.
 	ExtractedResults []string `json:"extracted-results,omitempty"`
+	// ExtractedValues are the optional extracted results keyed by the name
+	// of their extractor, suffixed by their index when there are several.
+	ExtractedValues map[string]string `json:"extracted-values,omitempty"`
 	// Request is the optional, dumped request for the match.
 	Request string `json:"request,omitempty"`
 	// Response is the optional, dumped response for the match.
 	Response string `json:"response,omitempty"`
+	// RequestAttachment and ResponseAttachment reference the request and
+	// response of the alert stored as attachments instead of inlined.
+	RequestAttachment  string `json:"request-attachment,omitempty"`
+	ResponseAttachment string `json:"response-attachment,omitempty"`
 	// Metadata contains any optional metadata for the event
 	Metadata map[string]interface{} `json:"meta,omitempty"`
 	// IP is the IP address for the found result event.
@@ -146,7 +295,38 @@ type ResultEvent struct {
 	// MatcherStatus is the status of the match
 	MatcherStatus bool `json:"matcher-status"`
 	// Lines is the line count for the specified match
-	Lines []int `json:"matched-line"`
+	Lines []int `json:"matched-line,omitempty"`
+	// TemplateSource is the optional, possibly truncated, yaml source of the template
+	TemplateSource string `json:"template-source,omitempty"`
+	// FailureType is the type of failure of a failed match, either no-match or error.
+	FailureType string `json:"failure-type,omitempty"`
+	// FailureReason is the error which caused a failed match if any.
+	FailureReason string `json:"failure-reason,omitempty"`
+	// SchemaVersion is the version of the structure of the result, see ResultSchemaVersion.
+	SchemaVersion string `json:"schema_version,omitempty"`
+	// RawResponse is the optional, base64 encoded original response before its reconstruction.
+	RawResponse string `json:"raw-response,omitempty"`
+	// EventID identifies the result, later updates of the result refer to it.
+	EventID string `json:"event-id,omitempty"`
+	// AuditID, ScanID and JobID identify the scan the result belongs to.
+	AuditID string `json:"audit-id,omitempty"`
+	ScanID  string `json:"scan-id,omitempty"`
+	JobID   string `json:"job-id,omitempty"`
+	// Fingerprint is the optional hash of the fields identifying the finding,
+	// stable across scans for deduplication and correlation.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Seq is the sequence number of the result, increasing in the order
+	// the results are written so consumers can reorder them.
+	Seq uint64 `json:"seq,omitempty"`
+	// Truncated is set when fields of the result were truncated to their configured
+	// limits or raw fields of the alert to fit the maximum alert size.
+	Truncated bool `json:"truncated,omitempty"`
+	// Latency is the response time in milliseconds of the request of the match.
+	// It is set by the protocol emitting the result, the writer only forwards it.
+	Latency int64 `json:"latency-ms,omitempty"`
+	// TLS is the certificate of the https server of the match. It is set
+	// by the protocol emitting the result, the writer only forwards it.
+	TLS *TLSCertificate `json:"tls,omitempty"`
 
 	FileToIndexPosition map[string]int `json:"-"`
 }
@@ -159,20 +339,45 @@ func NewStandardWriter(options *types.Options) (*StandardWriter, error) {
 	}
 	auroraColorizer := aurora.NewAurora(!options.NoColor)
 
-	var outputFile io.WriteCloser
-	if options.Output != "" {
-		output, err := newFileOutputWriter(options.Output, resumeBool)
+	newOutputFile := func(path string) (io.WriteCloser, error) {
+		if path == "" {
+			return nil, nil
+		}
+		output, err := newFileOutputWriter(path, resumeBool)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create output file")
 		}
-		outputFile = output
+		output.maxSize = int64(options.OutputRotateSize)
+		output.maxAge = options.OutputRotateInterval
+		return output, nil
+	}
+	outputFile, err := newOutputFile(options.Output)
+	if err != nil {
+		return nil, err
+	}
+	jsonOutputFile, err := newOutputFile(options.JSONOutput)
+	if err != nil {
+		return nil, err
+	}
+	textOutputFile, err := newOutputFile(options.TextOutput)
+	if err != nil {
+		return nil, err
 	}
 	var traceOutput io.WriteCloser
 	if options.TraceLogFile != "" {
+		if err := validateTraceFormat(options.TraceLogFormat); err != nil {
+			return nil, err
+		}
 		output, err := newFileOutputWriter(options.TraceLogFile, resumeBool)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not create output file")
 		}
+		if options.TraceLogFormat == TraceFormatCSV && output.size == 0 {
+			if _, err := output.Write(traceCSVHeader); err != nil {
+				output.Close()
+				return nil, errors.Wrap(err, "could not write trace log header")
+			}
+		}
 		traceOutput = output
 	}
 	var errorOutput io.WriteCloser
@@ -183,6 +388,52 @@ func NewStandardWriter(options *types.Options) (*StandardWriter, error) {
 		}
 		errorOutput = output
 	}
+	fieldFilter, err := newFieldFilter(options.IncludeFields, options.ExcludeFields)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid output fields")
+	}
+	templateFilter, err := newTemplateFilter(options.SuppressTemplateIDs, options.OnlyTemplateIDs)
+	if err != nil {
+		return nil, err
+	}
+	hostFilter, err := newHostFilter(options.AllowHosts, options.DenyHosts)
+	if err != nil {
+		return nil, err
+	}
+	var fingerprintFields []string
+	if options.Fingerprint {
+		if fingerprintFields, err = newFingerprintFields(options.FingerprintFields); err != nil {
+			return nil, err
+		}
+	}
+	theme := options.ColorTheme
+	if options.NoColor {
+		theme = colorizer.ThemeMonochrome
+	}
+	severityColors, err := colorizer.NewTheme(auroraColorizer, theme)
+	if err != nil {
+		return nil, err
+	}
+	var sqliteOutput *sqliteWriter
+	if options.SQLiteOutput != "" {
+		output, err := newSQLiteWriter(options.SQLiteOutput)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create sqlite output")
+		}
+		sqliteOutput = output
+	}
+	var junitOutput *junitWriter
+	if options.JUnitExport != "" {
+		if junitOutput, err = newJUnitWriter(options.JUnitExport); err != nil {
+			return nil, err
+		}
+	}
+	var syslogOutput *syslogWriter
+	if options.SyslogTarget != "" {
+		if syslogOutput, err = newSyslogWriter(options.SyslogTarget, options.SyslogPriority); err != nil {
+			return nil, err
+		}
+	}
 	// Try to create output folder if it doesn't exist
 	if options.StoreResponse && !fileutil.FolderExists(options.StoreResponseDir) {
 		if err := fileutil.CreateFolder(options.StoreResponseDir); err != nil {
@@ -195,7 +446,8 @@ func NewStandardWriter(options *types.Options) (*StandardWriter, error) {
 	var tempAstraWebhookUrl, tempAstraApiServiceName string
 
 	tempAstraMeta.Event = "alert"
-	tempAstraMeta.Hostname = "k8s"
+	tempAstraMeta.Hostname = metaHostname(options.MetaHostname)
+	tempAstraMeta.Extra = options.ExtraMeta
 
 	value, ok := os.LookupEnv("auditId")
 	if ok {
@@ -239,38 +491,367 @@ func NewStandardWriter(options *types.Options) (*StandardWriter, error) {
 		tempAstraApiServiceName = value
 	}
 
+	tempAstraWebhookUrl, err = expandEnvPlaceholders(tempAstraWebhookUrl)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not expand webhook url")
+	}
+	tempAstraApiServiceName, err = expandEnvPlaceholders(tempAstraApiServiceName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not expand api service name")
+	}
+	if err := validateWebhookURL(tempAstraWebhookUrl); err != nil {
+		return nil, err
+	}
+	backupWebhookURL, err := expandEnvPlaceholders(options.BackupWebhookURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not expand backup webhook url")
+	}
+	if backupWebhookURL != "" {
+		if err := validateWebhookURL(backupWebhookURL); err != nil {
+			return nil, errors.Wrap(err, "invalid backup webhook url")
+		}
+	}
+	severityWebhooks, err := newSeverityWebhooks(options.SeverityWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	statusScheme, statusMethod, statusPath := options.StatusScheme, options.StatusMethod, options.StatusPathTemplate
+	if statusScheme == "" {
+		statusScheme = "http"
+	}
+	if statusMethod == "" {
+		statusMethod = defaultStatusMethod
+	}
+	if statusPath == "" {
+		statusPath = defaultStatusPathTemplate
+	}
+	if err := validateStatusRequest(statusScheme, statusMethod, statusPath); err != nil {
+		return nil, err
+	}
+	if err := validateApiServiceName(tempAstraApiServiceName, statusScheme, statusPath, tempAstraMeta.ScanId); err != nil {
+		return nil, err
+	}
+	if err := validateExtraMeta(options.ExtraMeta); err != nil {
+		return nil, err
+	}
+	if err := validateAlertFormat(options.AlertFormat); err != nil {
+		return nil, err
+	}
+	if err := validateOutputFormat(options.OutputFormat); err != nil {
+		return nil, err
+	}
+	if err := validateEnvelopeVersion(options.EnvelopeVersion); err != nil {
+		return nil, err
+	}
+	if err := validateLineEndings(options.ResponseLineEndings); err != nil {
+		return nil, err
+	}
+	if err := validateFieldLimits(options.FieldLimits); err != nil {
+		return nil, err
+	}
+	if options.WebhookBatchByHost && options.WebhookTemplate != "" {
+		return nil, errors.New("webhook template can't be used with webhook batching by host")
+	}
+	if options.WebhookOmitBodyToken && options.WebhookAuthHeader == "" {
+		return nil, errors.New("webhook token can't be omitted from the body without an auth header")
+	}
+	if options.WebhookQueueSize > 0 {
+		if err := validateDropPolicy(options.WebhookDropPolicy); err != nil {
+			return nil, err
+		}
+	}
+
 	writer := &StandardWriter{
-		json:                options.JSONL,
+		json:                options.JSONL || options.OutputFormat == OutputFormatECS || options.OutputFormat == OutputFormatCycloneDX,
+		outputFormat:        options.OutputFormat,
 		jsonReqResp:         options.JSONRequests,
 		noMetadata:          options.NoMeta,
 		matcherStatus:       options.MatcherStatus,
+		compactOutput:       options.CompactOutput,
+		prettyJSON:          options.PrettyJSON,
+		screenBodyLimit:     options.ScreenBodyLimit,
 		timestamp:           options.Timestamp,
 		aurora:              auroraColorizer,
 		mutex:               &sync.Mutex{},
 		outputFile:          outputFile,
+		jsonOutputFile:      jsonOutputFile,
+		textOutputFile:      textOutputFile,
 		traceFile:           traceOutput,
 		errorFile:           errorOutput,
-		severityColors:      colorizer.New(auroraColorizer),
+		sqliteOutput:        sqliteOutput,
+		junitOutput:         junitOutput,
+		syslogOutput:        syslogOutput,
+		maxAlertBytes:       options.MaxAlertBytes,
+		verbose:             options.Verbose,
+		silent:              options.Silent,
+		rawResponse:         options.IncludeRawResponse,
+		authHeader:          options.WebhookAuthHeader,
+		authScheme:          options.WebhookAuthScheme,
+		authToken:           tempAstraMeta.WebhookToken,
+		responseBody:        options.IncludeResponseBody,
+		noReconstruction:    options.DisableResponseReconstruction || options.DisableFileResponseReconstruction,
+		noWebhookRecon:      options.DisableResponseReconstruction || options.DisableWebhookResponseReconstruction,
+		statusLine:          options.ResponseStatusLine,
+		lineEndings:         options.ResponseLineEndings,
+		fieldLimits:         options.FieldLimits,
+		traceFormat:         options.TraceLogFormat,
+		severityColors:      severityColors,
 		storeResponse:       options.StoreResponse,
 		storeResponseDir:    options.StoreResponseDir,
+		storeDateFolders:    options.StoreResponseDateFolders,
+		maxStoredResponses:  options.MaxStoredResponsesPerKey,
+		maxOpenStored:       options.MaxOpenStoredFiles,
 		AstraMeta:           tempAstraMeta,
 		AstraWebhook:        tempAstraWebhookUrl,
+		backupWebhook:       backupWebhookURL,
+		severityWebhooks:    severityWebhooks,
+		excludeFromOutput:   options.AlertExcludeOutput,
+		maxAlerts:           int64(options.MaxAlerts),
+		quotaSkipOutput:     options.MaxAlertsSkipOutput,
+		extractedValues:     options.ExtractedValues,
 		AstraApiServiceName: tempAstraApiServiceName,
+		webhookErrors:       options.WebhookErrors,
+		webhookRetries:      options.WebhookRetries,
+		webhookRetryDelay:   defaultWebhookRetryDelay,
+		httpClient:          &http.Client{Timeout: webhookTimeout},
+		webhookSecret:       options.WebhookSecret,
+		gzipThreshold:       options.WebhookGzipThreshold,
+		alertFormat:         options.AlertFormat,
+		envelopeVersion:     options.EnvelopeVersion,
+		normalizeMatchedAt:  options.NormalizeMatchedAt,
+		severityOverrides:   options.SeverityOverrides,
+		fieldFilter:         fieldFilter,
+		templateFilter:      templateFilter,
+		hostFilter:          hostFilter,
+		fingerprintFields:   fingerprintFields,
+		templateSource:      options.IncludeTemplateSource,
+		templateSourceLimit: options.TemplateSourceLimit,
+		enrichIP:            options.ResolveIP,
+		statusScheme:        statusScheme,
+		statusMethod:        strings.ToUpper(statusMethod),
+		statusPath:          statusPath,
+		tolerateOutputErr:   options.TolerateOutputErrors,
+	}
+	if options.Stdout {
+		writer.stdout = os.Stdout
+	}
+	if options.OutputFormat == OutputFormatCycloneDX {
+		writer.cyclonedxReport = newCycloneDXReport()
+	}
+	if len(options.AlertExcludeSeverities) > 0 {
+		writer.excludedSeverities = make(map[severity.Severity]struct{}, len(options.AlertExcludeSeverities))
+		for _, value := range options.AlertExcludeSeverities {
+			writer.excludedSeverities[value] = struct{}{}
+		}
+	}
+	if options.WebhookOmitBodyToken {
+		// the token is only sent in the auth header
+		writer.AstraMeta.WebhookToken = ""
+	}
+	writer.deliveryCtx, writer.cancelDeliveries = context.WithCancel(context.Background())
+	// The webhook and the status api share the rate limit
+	var limiter *rate.Limiter
+	if options.WebhookRateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(options.WebhookRateLimit), 1)
+	}
+	writer.httpClient.Transport = wrapTransport(&unixSocketTransport{}, options.DryRun, limiter)
+	writer.statusClient = writer.httpClient
+	if options.StatusCABundle != "" || options.StatusInsecureSkipVerify {
+		tlsConfig, err := statusTLSConfig(options.StatusCABundle, options.StatusInsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		writer.statusClient = &http.Client{Transport: wrapTransport(transport, options.DryRun, limiter)}
+	}
+	if options.WebhookTransport != nil {
+		// test only, the injected transport sends all the requests
+		writer.httpClient.Transport = wrapTransport(options.WebhookTransport, options.DryRun, limiter)
+		writer.statusClient = writer.httpClient
+	}
+	if writer.alertTransport, err = newAlertTransport(options, writer.httpClient); err != nil {
+		return nil, err
+	}
+	if writer.attachmentStore, err = newAttachmentStore(options, writer.httpClient); err != nil {
+		return nil, err
+	}
+	if options.WebhookTemplate != "" {
+		webhookTemplate, err := loadWebhookTemplate(options.WebhookTemplate)
+		if err != nil {
+			return nil, err
+		}
+		writer.webhookTemplate = webhookTemplate
+	}
+	if options.WebhookBatchByHost {
+		writer.alertBatcher = newAlertBatcher(options.WebhookBatchSize, writer.dispatchAlert)
+	}
+	if options.WebhookQueueSize > 0 {
+		writer.alertQueue = newAlertQueue(options.WebhookQueueSize, options.WebhookWorkers, options.WebhookDropPolicy, writer.deliverAlert)
+		writer.drainTimeout = options.WebhookDrainTimeout
+	}
+	if options.WebhookSpoolFile != "" {
+		writer.spool = newAlertSpool(options.WebhookSpoolFile)
+		if err := writer.ReplaySpool(); err != nil {
+			gologger.Warning().Msgf("Could not replay spooled alerts: %s\n", err)
+		}
 	}
 
-	// Changing state to running
-	gologger.Info().Msg("Changing scan state to running")
-	writer.sendStatusChangeRequest("RUNNING")
+	writer.resume = resumeBool
+	writer.requireStatusAPI = options.RequireStatusAPI
+	if options.RequireStatusAPI && !resumeBool {
+		// strict deployments don't scan without the status api, the state
+		// is changed eagerly while scan.started waits for Start and the
+		// scan info. A resumed scan is already running.
+		if err := writer.changeScanStatus("RUNNING"); err != nil {
+			return nil, errors.Wrap(err, "could not change scan state to running")
+		}
+		writer.statusRunning = true
+	}
 	return writer, nil
 }
 
+// SetScanInfo sets the number of targets and templates of the scan
+// sent along with the scan started and complete events. It must be
+// called before the writer is started.
+func (w *StandardWriter) SetScanInfo(targets, templates int) {
+	w.scanMutex.Lock()
+	defer w.scanMutex.Unlock()
+
+	w.scanTargets = targets
+	w.scanTemplates = templates
+}
+
+// Start changes the scan state to running and triggers the `scan.started`
+// event, or the `scan.resumed` event when resuming a scan. It is called
+// implicitly by the first result or on Close if not called before.
+func (w *StandardWriter) Start() {
+	w.startOnce.Do(func() {
+		w.scanMutex.Lock()
+		w.scanStartTime = time.Now()
+		w.scanMutex.Unlock()
+
+		if w.resume {
+			// The scan was already started, let the webhook know it was resumed
+			// instead of reporting a duplicate start.
+			w.logInfo("Resuming scan, skipping change of scan state to running\n")
+			w.sendScanResumed()
+			return
+		}
+
+		// Changing state to running, a failure is only fatal when the
+		// status api is required in which case it was changed eagerly
+		w.logInfo("Changing scan state to running\n")
+		if err := w.sendStatusChangeRequest("RUNNING"); err != nil {
+			gologger.Warning().Msgf("Could not change scan state to running: %s\n", err)
+		}
+	})
+}
+
+// currentTime returns the current time of the writer clock
+func (w *StandardWriter) currentTime() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
+
+// logInfo logs the scan level messages unless the writer is silent
+func (w *StandardWriter) logInfo(format string, args ...interface{}) {
+	if !w.silent {
+		gologger.Info().Msgf(format, args...)
+	}
+}
+
+// logVerbose logs the per event messages when the writer is verbose
+func (w *StandardWriter) logVerbose(format string, args ...interface{}) {
+	if w.verbose && !w.silent {
+		gologger.Verbose().Msgf(format, args...)
+	}
+}
+
+// scanContext is the context of the scan level webhook events
+type scanContext struct {
+	Reason    string     `json:"reason"`
+	Targets   int        `json:"targets,omitempty"`
+	Templates int        `json:"templates,omitempty"`
+	StartTime time.Time  `json:"start-time"`
+	EndTime   *time.Time `json:"end-time,omitempty"`
+}
+
+// scanEventContext returns the context of a scan level event with reason
+func (w *StandardWriter) scanEventContext(reason string, complete bool) json.RawMessage {
+	w.scanMutex.Lock()
+	context := scanContext{
+		Reason:    reason,
+		Targets:   w.scanTargets,
+		Templates: w.scanTemplates,
+		StartTime: w.scanStartTime,
+	}
+	w.scanMutex.Unlock()
+
+	if complete {
+		endTime := time.Now()
+		context.EndTime = &endTime
+	}
+	data, _ := jsonEncoder.Marshal(context)
+	return data
+}
+
+// sendScanResumed triggers the `scan.resumed` event on the webhook
+func (w *StandardWriter) sendScanResumed() {
+	resp, err := w.sendAstraEvent(context.Background(), "scan.resumed", w.scanEventContext("Scan Resumed successfully", false), "")
+	if err != nil {
+		gologger.Warning().Msgf("Could not send scan resumed event: %s\n", err)
+		return
+	}
+	resp.Body.Close()
+
+	w.logVerbose("Request status received -> %s for alert\n", resp.Status)
+}
+
 type sendStatusChangeRequestStruct struct {
-	StateChange json.RawMessage `json:"state_change"`
+	StateChange json.RawMessage   `json:"state_change"`
+	Meta        map[string]string `json:"meta,omitempty"`
 }
 
 // Function for updating status of scan in database
-func (w *StandardWriter) sendStatusChangeRequest(action string) {
-	gologger.Info().Msgf("Sending status change request with action -> %s\n", action)
+func (w *StandardWriter) sendStatusChangeRequest(action string) error {
+	w.logInfo("Sending status change request with action -> %s\n", action)
+	var statusErr error
+	if action != "RUNNING" || !w.statusRunning {
+		statusErr = w.changeScanStatus(action)
+	}
+	if statusErr != nil {
+		if w.requireStatusAPI && action == "RUNNING" {
+			return statusErr
+		}
+		gologger.Warning().Msgf("Could not change scan state to %s, continuing without the status api: %s\n", strings.ToLower(action), statusErr)
+	}
+
+	// Trigger `scan.complete` event on webhook
+	w.logVerbose("Triggering event on webhook url\n")
+
+	var resp_ *http.Response
+	var err error
+	if action == "RUNNING" {
+		resp_, err = w.sendAstraEvent(context.Background(), "scan.started", w.scanEventContext("Scan Started successfully", false), "")
+	} else {
+		resp_, err = w.sendAstraEvent(context.Background(), "scan.complete", w.scanEventContext("Scan Completed successfully", true), "")
+	}
+	if err != nil {
+		w.logVerbose("Could not send %s event: %s\n", strings.ToLower(action), err)
+		return statusErr
+	}
+	defer resp_.Body.Close()
+	w.logVerbose("Request status received -> %s for alert\n", resp_.Status)
+	return statusErr
+}
+
+// changeScanStatus sends the request changing the state of the scan to
+// the status api, returning an error if the api is unreachable or
+// rejects the change.
+func (w *StandardWriter) changeScanStatus(action string) error {
 	var tempRequest map[string]string
 
 	if action == "RUNNING" {
@@ -279,52 +860,184 @@ func (w *StandardWriter) sendStatusChangeRequest(action string) {
 		tempRequest = map[string]string{"status": action}
 	}
 
-	tempRequestBody, _ := json.Marshal(tempRequest)
-	temp_ := sendStatusChangeRequestStruct{tempRequestBody}
+	tempRequestBody, _ := jsonEncoder.Marshal(tempRequest)
+	temp_ := sendStatusChangeRequestStruct{StateChange: tempRequestBody, Meta: w.AstraMeta.Extra}
 
-	postBody, _ := json.Marshal(temp_)
+	postBody, _ := jsonEncoder.Marshal(temp_)
 	responseBody := bytes.NewBuffer(postBody)
-	req, _ := http.NewRequest("PATCH", fmt.Sprintf("http://%s/api/nuclei/%s", w.AstraApiServiceName, w.AstraMeta.ScanId), responseBody)
+	scheme, method, path := w.statusScheme, w.statusMethod, w.statusPath
+	if scheme == "" {
+		scheme = "http"
+	}
+	if method == "" {
+		method = defaultStatusMethod
+	}
+	if path == "" {
+		path = defaultStatusPathTemplate
+	}
+	req, err := http.NewRequest(method, statusChangeURL(scheme, w.AstraApiServiceName, path, w.AstraMeta.ScanId), responseBody)
+	if err != nil {
+		return errors.Wrap(err, "could not create status change request")
+	}
 
 	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
+	w.setAuthHeader(req)
+	client := w.statusClient
+	if client == nil {
+		client = w.httpClient
+	}
 	resp, err := client.Do(req)
-
 	if err != nil {
-		panic(err)
+		return errors.Wrap(err, "could not send status change request")
 	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	w.logVerbose("Status code received for `status change api` -> %s\n", resp.Status)
 
-	gologger.Info().Msgf("Status code received for `status change api` -> %s\n", resp.Status)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status api returned %s", resp.Status)
+	}
+	w.metrics.statusChanges.Add(1)
+	return nil
+}
 
-	// Trigger `scan.complete` event on webhook
-	gologger.Info().Msg("Triggering event on webhook url")
+// defaultStatusMethod and defaultStatusPathTemplate are the http method
+// and path of the api service request updating the status of the scan.
+const (
+	defaultStatusMethod       = http.MethodPatch
+	defaultStatusPathTemplate = "/api/nuclei/{scanId}"
+)
 
-	tempAstraRequest := AstraAlertRequest{}
-	if action == "RUNNING" {
-		w.AstraMeta.Event = "scan.started"
-		tempAstraRequest.Context = []byte(`{"reason":"Scan Started successfully"}`)
-	} else {
-		w.AstraMeta.Event = "scan.complete"
-		tempAstraRequest.Context = []byte(`{"reason":"Scan Completed successfully"}`)
-	}
-	tempAstraRequest.Meta = w.AstraMeta
+// statusPathPlaceholderRegex matches the {name} placeholders of the status path template
+var statusPathPlaceholderRegex = regexp.MustCompile(`\{([^{}]*)\}`)
 
-	postBody_, _ := json.Marshal(tempAstraRequest)
-	responseBody_ := bytes.NewBuffer(postBody_)
+// statusChangeURL returns the api service url updating the status of the scan,
+// expanding the {scanId} and {serviceName} placeholders of the path template.
+func statusChangeURL(scheme, serviceName, pathTemplate, scanID string) string {
+	path := strings.NewReplacer("{scanId}", url.PathEscape(scanID), "{serviceName}", url.PathEscape(serviceName)).Replace(pathTemplate)
+	return fmt.Sprintf("%s://%s%s", scheme, serviceName, path)
+}
 
-	resp_, _ := http.Post(w.AstraWebhook, "application/json", responseBody_)
+// validateStatusRequest validates the scheme, the http method and the
+// path template of the status change request.
+func validateStatusRequest(scheme, method, pathTemplate string) error {
+	if scheme != "http" && scheme != "https" {
+		return fmt.Errorf("invalid status scheme %q: expected http or https", scheme)
+	}
+	switch strings.ToUpper(method) {
+	case http.MethodPatch, http.MethodPut, http.MethodPost:
+	default:
+		return fmt.Errorf("invalid status method %q: expected one of PATCH, PUT or POST", method)
+	}
+	if !strings.HasPrefix(pathTemplate, "/") {
+		return fmt.Errorf("invalid status path template %q: expected an absolute path", pathTemplate)
+	}
+	for _, match := range statusPathPlaceholderRegex.FindAllStringSubmatch(pathTemplate, -1) {
+		if match[1] != "scanId" && match[1] != "serviceName" {
+			return fmt.Errorf("invalid status path template %q: unknown placeholder %s", pathTemplate, match[0])
+		}
+	}
+	if strings.ContainsAny(statusPathPlaceholderRegex.ReplaceAllString(pathTemplate, ""), "{}?#") {
+		return fmt.Errorf("invalid status path template %q: expected a path without query or fragment", pathTemplate)
+	}
+	return nil
+}
 
-	gologger.Info().Msgf("Request status received -> %s for alert\n", resp_.Status)
+// expandEnvPlaceholders expands the ${VAR} placeholders in value from
+// the environment, returning an error if a referenced variable is not set.
+func expandEnvPlaceholders(value string) (string, error) {
+	var missing []string
+	expanded := envPlaceholderRegex.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := envPlaceholderRegex.FindStringSubmatch(placeholder)[1]
+		envValue, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return envValue
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
 
+// validateApiServiceName validates that the api service name forms
+// a valid host[:port] in the status change url.
+func validateApiServiceName(serviceName, scheme, pathTemplate, scanID string) error {
+	if serviceName == "" {
+		return errors.New("api service name is empty")
+	}
+	parsed, err := url.Parse(statusChangeURL(scheme, serviceName, pathTemplate, scanID))
+	if err != nil {
+		return errors.Wrapf(err, "invalid api service name %q", serviceName)
+	}
+	if parsed.Host != serviceName || parsed.Hostname() == "" {
+		return fmt.Errorf("invalid api service name %q: expected host[:port] without scheme or path", serviceName)
+	}
+	return nil
 }
 
 type AstraMeta struct {
-	Event        string `json:"event"`
-	AuditId      string `json:"auditId"`
-	JobId        string `json:"jobId"`
-	ScanId       string `json:"scanId"`
-	WebhookToken string `json:"webhookToken"`
-	Hostname     string `json:"hostname"`
+	Event          string `json:"event,omitempty"`
+	AuditId        string `json:"auditId"`
+	JobId          string `json:"jobId"`
+	ScanId         string `json:"scanId"`
+	WebhookToken   string `json:"webhookToken,omitempty"`
+	Hostname       string `json:"hostname"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// Extra contains custom fields merged into the meta, eg. tenant or
+	// environment identifiers. Reserved meta fields can't be overridden.
+	Extra map[string]string `json:"-"`
+}
+
+// metaHostname returns the hostname sent in the meta, the override if
+// set or else the hostname of the machine.
+func metaHostname(override string) string {
+	if override != "" {
+		return override
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "unknown"
+	}
+	return hostname
+}
+
+// reservedMetaKeys are the meta fields extra meta fields can't override
+var reservedMetaKeys = map[string]struct{}{
+	"event": {}, "auditId": {}, "jobId": {}, "scanId": {}, "webhookToken": {}, "hostname": {}, "idempotencyKey": {},
+}
+
+// MarshalJSON marshals the meta merging the extra fields into it
+func (meta AstraMeta) MarshalJSON() ([]byte, error) {
+	type astraMeta AstraMeta
+	data, err := jsonEncoder.Marshal(astraMeta(meta))
+	if err != nil || len(meta.Extra) == 0 {
+		return data, err
+	}
+	fields := make(map[string]interface{})
+	if err := decodeJSONNumbers(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range meta.Extra {
+		if _, reserved := reservedMetaKeys[key]; !reserved {
+			fields[key] = value
+		}
+	}
+	return jsonEncoder.Marshal(fields)
+}
+
+// validateExtraMeta validates that the extra meta fields don't override reserved ones
+func validateExtraMeta(extra map[string]string) error {
+	for key := range extra {
+		if key == "" {
+			return errors.New("invalid extra meta: empty key")
+		}
+		if _, reserved := reservedMetaKeys[key]; reserved {
+			return fmt.Errorf("invalid extra meta: %q is a reserved meta field", key)
+		}
+	}
+	return nil
 }
 
 // Request struct that will be used for astra alert's.
@@ -333,99 +1046,631 @@ type AstraAlertRequest struct {
 	Context json.RawMessage `json:"context"`
 }
 
-// This function will extract headers and other required data from HTTP raw response string
+// statusLinePattern matches the status line of a response, capturing its version and status code
+var statusLinePattern = regexp.MustCompile(`^HTTP/(\d+\.\d+)\s+(\d+)(?:\s|$)`)
+
+// headerLinePattern matches a header line of a response, capturing its name and value
+var headerLinePattern = regexp.MustCompile(`^([\w-]+):\s*(.*)$`)
+
+// maxResponseLineSize is the maximum size of a status or header line of a response
+const maxResponseLineSize = 1024 * 1024
+
+// This function will extract headers and other required data from HTTP raw response string.
+// The response is scanned line by line up to the end of its headers so
+// the body, which can be large, is neither scanned nor copied.
 func extractResponseData(rawResponse string) (string, int, map[string]string) {
 	headers := make(map[string]string)
-	headerPattern := regexp.MustCompile(`(?m)^([\w-]+):\s*([^\n\r]*)[\n\r]+`)
+	httpVersion := ""
+	statusCode := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(rawResponse))
+	scanner.Buffer(make([]byte, 0, 4096), maxResponseLineSize)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			break
+		}
+		if first {
+			if match := statusLinePattern.FindSubmatch(line); match != nil {
+				httpVersion = string(match[1])
+				statusCode, _ = strconv.Atoi(string(match[2]))
+				continue
+			}
+		}
+		if match := headerLinePattern.FindSubmatch(line); match != nil {
+			headers[strings.ToLower(string(match[1]))] = string(match[2])
+		}
+	}
+	return httpVersion, statusCode, headers
+}
 
-	// Find all matches of header fields in the raw response string
-	matches := headerPattern.FindAllStringSubmatch(rawResponse, -1)
+// extractResponseBody returns the body of the raw response string, decoding
+// it if sent with chunked transfer encoding. Malformed chunked bodies are
+// returned as is.
+func extractResponseBody(rawResponse string, headers map[string]string) string {
+	var body string
+	if index := strings.Index(rawResponse, "\r\n\r\n"); index != -1 {
+		body = rawResponse[index+4:]
+	} else if index := strings.Index(rawResponse, "\n\n"); index != -1 {
+		body = rawResponse[index+2:]
+	} else {
+		return ""
+	}
+	if !strings.Contains(strings.ToLower(headers["transfer-encoding"]), "chunked") {
+		return body
+	}
+	// the chunks are decoded straight into the returned string
+	decoded := &strings.Builder{}
+	decoded.Grow(len(body))
+	if _, err := io.Copy(decoded, httputil.NewChunkedReader(strings.NewReader(body))); err != nil {
+		return body
+	}
+	return decoded.String()
+}
 
-	// Loop through the matches and extract the header name and value
-	for _, match := range matches {
-		name := strings.ToLower(match[1])
-		value := match[2]
-		headers[name] = value
+// reconstructResponse rebuilds the response from its status line and
+// headers, along with its body when asked to.
+func (w *StandardWriter) reconstructResponse(response string) string {
+	// Extract required data from response string and update response string
+	httpVersion, statusCode, headers := extractResponseData(response)
+	if w.statusLine {
+		return normalizeLineEndings(reconstructHTTPResponse(response, httpVersion, statusCode, headers, w.responseBody), w.lineEndings)
+	}
+	builder := getBuffer()
+	defer putBuffer(builder)
+	fmt.Fprintf(builder, "HTTP version: %s\nStatus code: %d\n", httpVersion, statusCode)
+	for name, value := range headers {
+		builder.WriteString(name)
+		builder.WriteString(": ")
+		builder.WriteString(value)
+		builder.WriteString("\n")
+	}
+	if w.responseBody {
+		body := extractResponseBody(response, headers)
+		builder.Grow(len(body) + 1)
+		builder.WriteString("\n")
+		builder.WriteString(body)
 	}
+	return normalizeLineEndings(builder.String(), w.lineEndings)
+}
 
-	// Extract the status code and HTTP version from the raw response string
-	statusPattern := regexp.MustCompile(`^HTTP/(\d+\.\d+)\s+(\d+)\s+.*`)
-	statusMatch := statusPattern.FindStringSubmatch(rawResponse)
-	httpVersion := ""
-	statusCode := 0
-	if len(statusMatch) > 2 {
-		httpVersion = statusMatch[1]
-		statusCode, _ = strconv.Atoi(statusMatch[2])
+// encodeResponse returns the base64 encoded response, reconstructed
+// unless noReconstruction is set, limited to the response field limit.
+func (w *StandardWriter) encodeResponse(event *ResultEvent, response string, noReconstruction bool) string {
+	if !noReconstruction {
+		response = w.reconstructResponse(response)
 	}
+	return w.encodeField(event, "response", response)
+}
 
-	return httpVersion, statusCode, headers
+// reconstructHTTPResponse rebuilds the response as a valid http response
+// starting with its status line, eg. HTTP/1.1 200 OK, instead of the prose
+// preamble. A response without a status line is reported as HTTP/1.1.
+func reconstructHTTPResponse(response, httpVersion string, statusCode int, headers map[string]string, withBody bool) string {
+	if httpVersion == "" {
+		httpVersion = "1.1"
+	}
+	builder := getBuffer()
+	defer putBuffer(builder)
+	builder.WriteString(strings.TrimSpace(fmt.Sprintf("HTTP/%s %03d %s", httpVersion, statusCode, http.StatusText(statusCode))))
+	builder.WriteString("\r\n")
+	for name, value := range headers {
+		builder.WriteString(name)
+		builder.WriteString(": ")
+		builder.WriteString(value)
+		builder.WriteString("\r\n")
+	}
+	builder.WriteString("\r\n")
+	if withBody {
+		builder.WriteString(extractResponseBody(response, headers))
+	}
+	return builder.String()
 }
 
 // Write writes the event to file and/or screen.
-func (w *StandardWriter) Write(event *ResultEvent) error {
+func (w *StandardWriter) Write(event *ResultEvent) (err error) {
+	defer w.recoverPanic("Write", &err)
+	return w.write(event, "")
+}
+
+// write writes the event to file and/or screen, sending it to the
+// webhook as the alert event, alert when empty.
+func (w *StandardWriter) write(event *ResultEvent, alertEvent string) error {
+	w.Start()
+
+	// Results of muted templates are dropped before being written anywhere
+	if w.templateFilter != nil && !w.templateFilter.allowed(event.TemplateID) {
+		return nil
+	}
+	// as are the results of out of scope hosts
+	if w.hostFilter != nil {
+		ip := event.IP
+		if ip == "" && w.hostFilter.matchesIPs() {
+			ip = w.resolveIP(event.Host)
+		}
+		if !w.hostFilter.allowed(event.Host, ip) {
+			return nil
+		}
+	}
+
 	// Enrich the result event with extra metadata on the template-path and url.
 	if event.TemplatePath != "" {
 		event.Template, event.TemplateURL = utils.TemplatePathURL(types.ToString(event.TemplatePath))
 	}
-	event.Timestamp = time.Now()
+	event.Timestamp = w.currentTime()
+	event.SchemaVersion = ResultSchemaVersion
+	if override, ok := w.severityOverrides[event.TemplateID]; ok {
+		event.Info.SeverityHolder.Severity = override
+	}
+	event.Info.SeverityHolder.Severity = normalizeSeverity(event.Info.SeverityHolder.Severity)
+	_, excluded := w.excludedSeverities[event.Info.SeverityHolder.Severity]
+	if excluded && w.excludeFromOutput {
+		return nil
+	}
+	// results over the alert quota of the scan are only written to the
+	// outputs, the failures don't use up the quota of the findings
+	overQuota := !excluded && alertEvent == "" && !w.reserveAlert()
+	if overQuota && w.quotaSkipOutput {
+		return nil
+	}
+	// failures aren't findings, only the matches are counted
+	if alertEvent == "" {
+		w.metrics.countSeverity(event.Info.SeverityHolder.Severity)
+		w.templateCounts.add(event.TemplateID)
+	}
+	promoteClassification(event)
+	promoteAuthorsAndTags(event)
+	if w.extractedValues {
+		event.ExtractedValues = namedExtractions(event.ExtractorName, event.ExtractedResults)
+	}
+	event.Metadata = normalizeMetadataNumbers(event.Metadata)
+	if w.normalizeMatchedAt {
+		event.Matched = normalizeMatchedAt(event.Matched)
+	}
+	if event.Type == "http" {
+		parseMatchedAt(event)
+	}
+	event.EventID = idempotencyKey(event)
+	event.AuditID, event.ScanID, event.JobID = w.AstraMeta.AuditId, w.AstraMeta.ScanId, w.AstraMeta.JobId
+	if w.enrichIP && event.IP == "" && event.Host != "" {
+		event.IP = w.resolveIP(event.Host)
+	}
+	if w.templateSource && event.TemplatePath != "" {
+		event.TemplateSource = w.readTemplateSource(event.TemplatePath)
+	}
+	if len(w.fingerprintFields) > 0 {
+		event.Fingerprint = fingerprint(event, w.fingerprintFields)
+	}
+
+	w.limitFields(event)
 
 	var data []byte
 	var err error
 
-	// Extract required data from response string and update response string
-	httpVersion, statusCode, headers := extractResponseData(event.Response)
-	newResponseString := fmt.Sprintf("HTTP version: %s\nStatus code: %d\n", httpVersion, statusCode)
-	for name, value := range headers {
-		newResponseString = newResponseString + fmt.Sprintf("%s: %s\n", name, value)
+	// The reconstruction below loses the body and the header order, keep
+	// the original bytes as proof when asked to.
+	if w.rawResponse && event.Response != "" {
+		event.RawResponse = w.encodeField(event, "raw-response", event.Response)
 	}
-	event.Response = newResponseString
 
-	event.Request = b64.StdEncoding.EncodeToString([]byte(event.Request))
-	event.Response = b64.StdEncoding.EncodeToString([]byte(event.Response))
+	// The written results and the webhook alerts can get their own
+	// representation of the response, the alert is formatted again with
+	// its own once the results are written.
+	fileResponse := w.encodeResponse(event, event.Response, w.noReconstruction)
+	webhookResponse := fileResponse
+	if w.noWebhookRecon != w.noReconstruction {
+		webhookResponse = w.encodeResponse(event, event.Response, w.noWebhookRecon)
+	}
+	request := w.encodeField(event, "request", event.Request)
+	event.Request, event.Response = request, fileResponse
+
+	// Raw interactsh request/response can carry binary (e.g. dns) data, encode
+	// them as well so the out-of-band proof survives the json encoding. The
+	// interaction is copied as it is shared with the interactsh client.
+	if event.Interaction != nil {
+		event.Interaction = encodeInteraction(event.Interaction)
+	}
+
+	if data, err = w.writeOutput(event); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
 
+	if w.sqliteOutput != nil {
+		rawJSON := data
+		if !w.json {
+			if rawJSON, err = w.formatJSON(event); err != nil {
+				return errors.Wrap(err, "could not format output")
+			}
+		}
+		if err := w.sqliteOutput.Write(event, rawJSON); err != nil {
+			return errors.Wrap(err, "could not write to sqlite output")
+		}
+	}
+	if w.junitOutput != nil {
+		w.junitOutput.Write(event)
+	}
+	if w.syslogOutput != nil {
+		syslogData := data
+		if !w.json {
+			syslogData = decolorizerRegex.ReplaceAll(data, []byte(""))
+		}
+		if err := w.syslogOutput.Write(syslogData); err != nil {
+			return errors.Wrap(err, "could not write to syslog")
+		}
+	}
+
+	if w.OnResult != nil {
+		w.runOnResult(event)
+	}
+
+	// Results of excluded severities are only written to the outputs
+	if excluded || overQuota {
+		return nil
+	}
+	if webhookResponse != fileResponse && w.json && w.jsonReqResp {
+		event.Request, event.Response = request, webhookResponse
+		if data, err = w.formatJSON(event); err != nil {
+			return errors.Wrap(err, "could not format alert")
+		}
+	}
+	alert := &alert{templateURL: event.TemplateURL, context: data, idempotencyKey: event.EventID, event: alertEvent}
+	// only the alert references the attachments, stored once it is
+	// delivered, the outputs keep the request and response inline
+	if w.attachmentStore != nil && w.json && w.jsonReqResp {
+		attached := *event
+		alert.attachments = &attached
+	}
+	alert.webhookURL = w.severityWebhooks[event.Info.SeverityHolder.Severity]
+	if w.maxAlertBytes > 0 && w.json {
+		if alert.context, err = w.fitAlertContext(event, data, alert.eventName(), alert.idempotencyKey); err != nil {
+			return err
+		}
+	}
+	if w.webhookTemplate != nil {
+		if alert.body, err = w.renderAlert(event, alert.eventName(), alert.idempotencyKey); err != nil {
+			return err
+		}
+	}
+	// failures aren't findings of the host and alerts routed by severity
+	// go to their own webhook, they are never batched
+	if w.alertBatcher != nil && alertEvent == "" && alert.webhookURL == "" {
+		w.alertBatcher.add(event.Host, alert)
+		return nil
+	}
+	w.dispatchAlert(alert)
+	return nil
+}
+
+// writeOutput formats the event and writes it to stdout and the output file if any.
+//
+// The mutex guards the sequence number along with the output file so the
+// results are written whole and in sequence order, the webhook delivery
+// happens outside of it so a slow webhook doesn't serialize all the writers.
+func (w *StandardWriter) writeOutput(event *ResultEvent) ([]byte, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.sequence++
+	event.Seq = w.sequence
+
+	var data []byte
+	var err error
 	if w.json {
 		data, err = w.formatJSON(event)
 	} else {
 		data = w.formatScreen(event)
 	}
 	if err != nil {
-		return errors.Wrap(err, "could not format output")
+		return nil, errors.Wrap(err, "could not format output")
 	}
 	if len(data) == 0 {
-		return nil
+		return data, nil
 	}
-	w.mutex.Lock()
-	defer w.mutex.Unlock()
+	// the other formats are only written to stdout and the output file
+	written := data
+	if w.outputFormat == OutputFormatECS {
+		if written, err = w.formatECS(event); err != nil {
+			return nil, errors.Wrap(err, "could not format output")
+		}
+	}
+	// the cyclonedx report is written on close instead of a line per result
+	if w.cyclonedxReport != nil {
+		w.cyclonedxReport.add(event)
+	}
+	if w.stdout != nil && w.cyclonedxReport == nil {
+		stdoutData := written
+		if !w.json {
+			stdoutData = decolorizerRegex.ReplaceAll(data, []byte(""))
+		}
+		// the line is built in a pooled buffer, appending the newline to
+		// the data would copy it as well
+		line := getBuffer()
+		line.Write(stdoutData)
+		line.WriteByte('\n')
+		_, _ = w.stdout.Write(line.Bytes())
+		putBuffer(line)
+	}
+	if w.outputFile != nil && w.cyclonedxReport == nil {
+		fileData := written
+		if !w.json {
+			fileData = decolorizerRegex.ReplaceAll(data, []byte(""))
+		} else if w.prettyJSON {
+			// only the file is indented, the webhook payload stays compact
+			indented := getBuffer()
+			defer putBuffer(indented)
+			if err := json.Indent(indented, written, "", "  "); err != nil {
+				return nil, errors.Wrap(err, "could not indent output")
+			}
+			fileData = indented.Bytes()
+		}
+		if err := w.writeOutputFile(w.outputFile, fileData); err != nil {
+			return nil, err
+		}
+	}
+	// the json and text output files get their format whatever the output one
+	if w.jsonOutputFile != nil {
+		jsonData := written
+		if !w.json {
+			if jsonData, err = w.formatJSON(event); err != nil {
+				return nil, errors.Wrap(err, "could not format json output")
+			}
+		}
+		if err := w.writeOutputFile(w.jsonOutputFile, jsonData); err != nil {
+			return nil, err
+		}
+	}
+	if w.textOutputFile != nil {
+		textData := data
+		if w.json {
+			textData = w.formatScreen(event)
+		}
+		if err := w.writeOutputFile(w.textOutputFile, decolorizerRegex.ReplaceAll(textData, []byte(""))); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
 
-	// _, _ = os.Stdout.Write(data)
-	// _, _ = os.Stdout.Write([]byte("\n"))
+// writeOutputFile writes the data to the output file, failing unless the
+// output errors are tolerated.
+func (w *StandardWriter) writeOutputFile(file io.Writer, data []byte) error {
+	if _, writeErr := file.Write(data); writeErr != nil {
+		// a full disk shouldn't abort the scan when tolerated, the
+		// results are still delivered to the webhook
+		if !w.tolerateOutputErr {
+			return errors.Wrap(writeErr, "could not write to output")
+		}
+		w.outputErrOnce.Do(func() {
+			gologger.Warning().Msgf("Could not write to output, continuing without it: %s\n", writeErr)
+		})
+	}
+	return nil
+}
 
-	gologger.Info().Msgf("Raising alert for -> %s\n", event.TemplateURL)
+// WriteRaw writes an already formatted json event to the output file and
+// forwards it to the webhook wrapped in the astra meta without re-marshalling.
+// The event must be valid json, the webhook template isn't applied to it.
+func (w *StandardWriter) WriteRaw(data []byte) error {
+	if !json.Valid(data) {
+		return errors.New("could not write raw event: invalid json")
+	}
+	w.Start()
 
-	tempRequest := AstraAlertRequest{}
+	if w.outputFile != nil {
+		w.mutex.Lock()
+		_, err := w.outputFile.Write(data)
+		w.mutex.Unlock()
+		if err != nil {
+			return errors.Wrap(err, "could not write to output")
+		}
+	}
 
-	w.AstraMeta.Event = "alert"
+	var fields struct {
+		TemplateURL string `json:"template-url"`
+	}
+	_ = json.Unmarshal(data, &fields)
+	alert := &alert{templateURL: fields.TemplateURL, context: data, idempotencyKey: rawIdempotencyKey(data)}
+	w.dispatchAlert(alert)
+	return nil
+}
 
-	tempMeta := w.AstraMeta
-	tempRequest.Meta = tempMeta
-	tempRequest.Context = data
+// dispatchAlert queues the alert for delivery or delivers it right away
+func (w *StandardWriter) dispatchAlert(alert *alert) {
+	if w.alertQueue != nil {
+		w.alertQueue.push(alert)
+		return
+	}
+	w.deliverAlert(alert)
+}
 
-	postBody, _ := json.Marshal(tempRequest)
-	responseBody := bytes.NewBuffer(postBody)
+// alertUpdatedEvent is the webhook event of an update of an already sent alert
+const alertUpdatedEvent = "alert.updated"
 
-	resp, err := http.Post(w.AstraWebhook, "application/json", responseBody)
+// alertUpdate is the context of an update of an already sent alert
+type alertUpdate struct {
+	EventID     string              `json:"event-id"`
+	Interaction *server.Interaction `json:"interaction"`
+	Timestamp   time.Time           `json:"timestamp"`
+}
 
-	gologger.Info().Msgf("Request status received -> %s for alert\n", resp.Status)
+// UpdateInteraction sends an alert.updated webhook event with an out-of-band
+// interaction received after the result with eventID was written, eg. by a
+// later interactsh poll.
+func (w *StandardWriter) UpdateInteraction(eventID string, interaction *server.Interaction) error {
+	if eventID == "" {
+		return errors.New("could not update interaction: empty event id")
+	}
+	if interaction == nil {
+		return errors.New("could not update interaction: nil interaction")
+	}
+	data, err := jsonEncoder.Marshal(alertUpdate{EventID: eventID, Interaction: encodeInteraction(interaction), Timestamp: time.Now()})
+	if err != nil {
+		return errors.Wrap(err, "could not marshal interaction update")
+	}
+	w.dispatchAlert(&alert{templateURL: eventID, event: alertUpdatedEvent, context: data, idempotencyKey: rawIdempotencyKey(data)})
+	return nil
+}
 
-	if w.outputFile != nil {
-		if !w.json {
-			data = decolorizerRegex.ReplaceAll(data, []byte(""))
+// encodeInteraction returns a copy of the interaction with its raw
+// request and response base64 encoded.
+func encodeInteraction(interaction *server.Interaction) *server.Interaction {
+	encoded := *interaction
+	encoded.RawRequest = b64.StdEncoding.EncodeToString([]byte(interaction.RawRequest))
+	encoded.RawResponse = b64.StdEncoding.EncodeToString([]byte(interaction.RawResponse))
+	return &encoded
+}
+
+// runOnResult invokes the result callback recovering from any panic
+// so a faulty callback doesn't take down the scan.
+func (w *StandardWriter) runOnResult(event *ResultEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			gologger.Warning().Msgf("Recovered from panic in result callback for %s: %v", event.TemplateID, r)
 		}
-		if _, writeErr := w.outputFile.Write(data); writeErr != nil {
-			return errors.Wrap(err, "could not write to output")
+	}()
+	w.OnResult(event)
+}
+
+// recoverPanic recovers a panic of the writer method so a bug in the
+// output doesn't crash the scan. The panic is logged, counted and
+// returned in err if not nil. It must be deferred by the method.
+func (w *StandardWriter) recoverPanic(method string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	w.metrics.panics.Add(1)
+	gologger.Error().Msgf("Recovered from panic in %s: %v\n%s", method, r, debug.Stack())
+	if err != nil {
+		*err = fmt.Errorf("recovered from panic in %s: %v", method, r)
+	}
+}
+
+// readTemplateSource returns the source of the template truncated to the
+// configured limit. Sources are cached as templates match many times,
+// missing templates are reported once and have an empty source.
+func (w *StandardWriter) readTemplateSource(templatePath string) string {
+	if source, ok := w.templateSources.Load(templatePath); ok {
+		return source.(string)
+	}
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		gologger.Warning().Msgf("Could not read template source %s: %s\n", templatePath, err)
+	}
+	if w.templateSourceLimit > 0 && len(data) > w.templateSourceLimit {
+		data = data[:w.templateSourceLimit]
+	}
+	source, _ := w.templateSources.LoadOrStore(templatePath, string(data))
+	return source.(string)
+}
+
+// promoteClassification promotes the cve, cwe and cvss score from
+// the template classification to top level fields of the event.
+func promoteClassification(event *ResultEvent) {
+	classification := event.Info.Classification
+	if classification == nil {
+		return
+	}
+	if !classification.CVEID.IsEmpty() {
+		event.CVE = classification.CVEID.ToSlice()
+	}
+	if !classification.CWEID.IsEmpty() {
+		event.CWE = classification.CWEID.ToSlice()
+	}
+	event.CVSSScore = classification.CVSSScore
+}
+
+// normalizeSeverity maps a missing or unrecognized severity to unknown
+// so the results are colorized, counted and filtered deterministically.
+func normalizeSeverity(value severity.Severity) severity.Severity {
+	if value <= severity.Undefined || value > severity.Unknown {
+		return severity.Unknown
+	}
+	return value
+}
+
+// promoteAuthorsAndTags promotes the authors and tags of the template
+// info to top level fields of the event, skipping blank values.
+func promoteAuthorsAndTags(event *ResultEvent) {
+	event.Authors = nonBlankValues(event.Info.Authors.ToSlice())
+	event.Tags = nonBlankValues(event.Info.Tags.ToSlice())
+}
+
+// namedExtractions returns the extracted results keyed by the name of the
+// extractor, eg. version or version[0] and version[1] for several results.
+// Results of unnamed extractors only have the list, nil is returned.
+func namedExtractions(extractorName string, results []string) map[string]string {
+	if extractorName == "" || len(results) == 0 {
+		return nil
+	}
+	if len(results) == 1 {
+		return map[string]string{extractorName: results[0]}
+	}
+	values := make(map[string]string, len(results))
+	for i, result := range results {
+		values[fmt.Sprintf("%s[%d]", extractorName, i)] = result
+	}
+	return values
+}
+
+// nonBlankValues returns the trimmed non blank values, nil if there are none
+func nonBlankValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
 		}
 	}
-	return nil
+	return result
+}
+
+// parseMatchedAt sets the scheme, port and path of the matched-at url of
+// the event. Values which are not urls are left untouched.
+func parseMatchedAt(event *ResultEvent) {
+	parsed, err := url.Parse(event.Matched)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return
+	}
+	event.MatchedScheme = strings.ToLower(parsed.Scheme)
+	event.MatchedPath = parsed.EscapedPath()
+	if event.MatchedPath == "" {
+		event.MatchedPath = "/"
+	}
+	if port := parsed.Port(); port != "" {
+		event.MatchedPort, _ = strconv.Atoi(port)
+		return
+	}
+	switch event.MatchedScheme {
+	case "http", "ws":
+		event.MatchedPort = 80
+	case "https", "wss":
+		event.MatchedPort = 443
+	}
+}
+
+// normalizeMatchedAt normalizes a matched-at url so the same logical match
+// is always reported the same way: the scheme and host are lowercased,
+// the path is resolved without a trailing slash and the query string and
+// fragment are removed. Values which are not urls are returned as is.
+func normalizeMatchedAt(matched string) string {
+	parsed, err := url.Parse(matched)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return matched
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if parsed.Path != "" {
+		parsed.Path = strings.TrimSuffix(path.Clean(parsed.Path), "/")
+	}
+	parsed.RawPath = ""
+	parsed.RawQuery = ""
+	parsed.ForceQuery = false
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String()
 }
 
 // JSONLogRequest is a trace/error log request written to file
@@ -433,6 +1678,8 @@ type JSONLogRequest struct {
 	Template string `json:"template"`
 	Input    string `json:"input"`
 	Error    string `json:"error"`
+	// Category is the category of the error, eg. timeout or dns, see errorCategory
+	Category string `json:"category,omitempty"`
 	Type     string `json:"type"`
 }
 
@@ -448,17 +1695,26 @@ func (w *StandardWriter) Request(templatePath, input, requestType string, reques
 	}
 	if unwrappedErr := utils.UnwrapError(requestErr); unwrappedErr != nil {
 		request.Error = unwrappedErr.Error()
+		// the whole chain is inspected, the innermost error may have lost
+		// the type of the failure
+		request.Category = errorCategory(requestErr)
 	} else {
 		request.Error = "none"
 	}
 
-	data, err := jsoniter.Marshal(request)
+	data, err := jsonEncoder.Marshal(request)
 	if err != nil {
 		return
 	}
+	// records are newline separated so the logs can be read back as jsonl
+	data = append(data, '\n')
 
 	if w.traceFile != nil {
-		_, _ = w.traceFile.Write(data)
+		traceData := data
+		if w.traceFormat == TraceFormatCSV {
+			traceData = request.csvRecord()
+		}
+		_, _ = w.traceFile.Write(traceData)
 	}
 
 	if requestErr != nil && w.errorFile != nil {
@@ -466,19 +1722,125 @@ func (w *StandardWriter) Request(templatePath, input, requestType string, reques
 	}
 }
 
+// Formats of the trace log
+const (
+	// TraceFormatJSONL writes a json object per line
+	TraceFormatJSONL = "jsonl"
+	// TraceFormatCSV writes a csv row per line after a header row
+	TraceFormatCSV = "csv"
+)
+
+// traceCSVHeader is the header row of the csv trace log
+var traceCSVHeader = []byte("template,input,error,type\n")
+
+// validateTraceFormat validates the trace log format
+func validateTraceFormat(format string) error {
+	switch format {
+	case "", TraceFormatJSONL, TraceFormatCSV:
+		return nil
+	}
+	return fmt.Errorf("invalid trace log format %q: expected %s or %s", format, TraceFormatJSONL, TraceFormatCSV)
+}
+
+// csvRecord returns the request as a csv row
+func (request *JSONLogRequest) csvRecord() []byte {
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+	_ = writer.Write([]string{request.Template, request.Input, request.Error, request.Type})
+	writer.Flush()
+	return buffer.Bytes()
+}
+
+// JSONLogError is an error record written to the error log file
+type JSONLogError struct {
+	Template  string `json:"template"`
+	Input     string `json:"input"`
+	Error     string `json:"error"`
+	ErrorType string `json:"error-type"`
+}
+
+// WriteError writes an error encountered for a template and input to the
+// error log, forwarding it as a scan.error event to the webhook if asked.
+func (w *StandardWriter) WriteError(templateID, input string, err error) {
+	if err == nil || (w.errorFile == nil && !w.webhookErrors) {
+		return
+	}
+	if w.webhookErrors {
+		w.Start()
+	}
+	unwrappedErr := utils.UnwrapError(err)
+	record := &JSONLogError{
+		Template:  templateID,
+		Input:     input,
+		Error:     unwrappedErr.Error(),
+		ErrorType: fmt.Sprintf("%T", unwrappedErr),
+	}
+	data, marshalErr := jsonEncoder.Marshal(record)
+	if marshalErr != nil {
+		return
+	}
+
+	if w.errorFile != nil {
+		_, _ = w.errorFile.Write(append(data, '\n'))
+	}
+	if w.webhookErrors {
+		resp, postErr := w.sendAstraEvent(w.deliveryContext(), "scan.error", data, "")
+		if postErr != nil {
+			gologger.Warning().Msgf("Could not send scan.error event: %s\n", postErr)
+			return
+		}
+		resp.Body.Close()
+	}
+}
+
 // Colorizer returns the colorizer instance for writer
 func (w *StandardWriter) Colorizer() aurora.Aurora {
 	return w.aurora
 }
 
-// Close closes the output writing interface
+// Close closes the output writing interface. It completes the scan
+// only once and is safe to call multiple times.
 func (w *StandardWriter) Close() {
-	gologger.Info().Msg("Execution completed successfully, triggering complete event")
+	w.closeOnce.Do(w.close)
+}
 
-	w.sendStatusChangeRequest("COMPLETE")
+// close completes the scan and closes the output files
+func (w *StandardWriter) close() {
+	w.Start()
+	w.logInfo("Execution completed successfully, triggering complete event\n")
 
-	if w.outputFile != nil {
-		w.outputFile.Close()
+	if w.alertBatcher != nil {
+		w.alertBatcher.flush()
+	}
+	if w.alertQueue != nil {
+		if !w.alertQueue.close(w.drainTimeout) {
+			gologger.Warning().Msgf("Timed out after %s waiting for queued alerts to be delivered\n", w.drainTimeout)
+			// abort the deliveries still waiting on the webhook or the rate limit
+			w.cancelDeliveries()
+		}
+		if dropped := w.alertQueue.Dropped(); dropped > 0 {
+			gologger.Warning().Msgf("Dropped %d alerts as the alert queue was full\n", dropped)
+		}
+	}
+	if w.alertTransport != nil {
+		if err := w.alertTransport.Close(); err != nil {
+			gologger.Warning().Msgf("Could not publish the outstanding alerts: %s\n", err)
+		}
+	}
+	w.quotaEvent.Wait()
+	w.sendScanSummary()
+	_ = w.sendStatusChangeRequest("COMPLETE")
+
+	if w.cyclonedxReport != nil {
+		if err := w.writeCycloneDXReport(); err != nil {
+			gologger.Warning().Msgf("Could not write cyclonedx report: %s\n", err)
+		}
+	}
+
+	for _, file := range []io.WriteCloser{w.outputFile, w.jsonOutputFile, w.textOutputFile} {
+		if file != nil {
+			file.Close()
+		}
 	}
 	if w.traceFile != nil {
 		w.traceFile.Close()
@@ -486,10 +1848,118 @@ func (w *StandardWriter) Close() {
 	if w.errorFile != nil {
 		w.errorFile.Close()
 	}
+	if w.sqliteOutput != nil {
+		if err := w.sqliteOutput.Close(); err != nil {
+			gologger.Warning().Msgf("Could not close sqlite output: %s\n", err)
+		}
+	}
+	if w.junitOutput != nil {
+		if err := w.junitOutput.Close(); err != nil {
+			gologger.Warning().Msgf("Could not write junit report: %s\n", err)
+		}
+	}
+	if w.storedPool != nil {
+		if err := w.storedPool.close(); err != nil {
+			gologger.Warning().Msgf("Could not close stored responses: %s\n", err)
+		}
+	}
+	if w.syslogOutput != nil {
+		if err := w.syslogOutput.Close(); err != nil {
+			gologger.Warning().Msgf("Could not close syslog output: %s\n", err)
+		}
+	}
+	if w.cancelDeliveries != nil {
+		w.cancelDeliveries()
+	}
+}
+
+// flusher is implemented by output files buffering data
+type flusher interface {
+	Flush() error
+}
+
+// Flush writes out the buffered output, trace and error files, waits for
+// the queued alerts to be delivered and syncs the stored responses to disk.
+// It is safe to call concurrently with the other writer methods.
+func (w *StandardWriter) Flush() error {
+	var err error
+	if w.alertBatcher != nil {
+		w.alertBatcher.flush()
+	}
+	if w.alertQueue != nil && !w.alertQueue.flush(w.drainTimeout) {
+		err = multierr.Append(err, fmt.Errorf("timed out after %s waiting for queued alerts to be delivered", w.drainTimeout))
+	}
+	for _, file := range []io.WriteCloser{w.outputFile, w.jsonOutputFile, w.textOutputFile, w.traceFile, w.errorFile} {
+		if f, ok := file.(flusher); ok {
+			if flushErr := f.Flush(); flushErr != nil {
+				err = multierr.Append(err, errors.Wrap(flushErr, "could not flush output file"))
+			}
+		}
+	}
+	if w.sqliteOutput != nil {
+		if flushErr := w.sqliteOutput.Flush(); flushErr != nil {
+			err = multierr.Append(err, flushErr)
+		}
+	}
+
+	w.storedFilesMutex.Lock()
+	storedFiles := w.storedFiles
+	w.storedFiles = nil
+	w.storedFilesMutex.Unlock()
+	for filename := range storedFiles {
+		if syncErr := syncFile(filename); syncErr != nil {
+			err = multierr.Append(err, errors.Wrap(syncErr, "could not sync stored response"))
+		}
+	}
+	return err
+}
+
+// storedFilePool returns the pool of the open stored response files
+func (w *StandardWriter) storedFilePool() *storedFilePool {
+	w.storedPoolOnce.Do(func() {
+		w.storedPool = newStoredFilePool(w.maxOpenStored)
+	})
+	return w.storedPool
+}
+
+// reserveStoredResponse counts a response stored in the file, returning
+// false once the maximum number of responses stored in it is reached.
+func (w *StandardWriter) reserveStoredResponse(filename string) bool {
+	if w.maxStoredResponses <= 0 {
+		return true
+	}
+	w.storedFilesMutex.Lock()
+	defer w.storedFilesMutex.Unlock()
+
+	if w.storedCounts == nil {
+		w.storedCounts = make(map[string]int)
+	}
+	count := w.storedCounts[filename]
+	if count >= w.maxStoredResponses {
+		// the count goes past the maximum once so the notice is logged once
+		if count == w.maxStoredResponses {
+			gologger.Debug().Msgf("Maximum of %d stored responses reached for %s, skipping the next ones\n", w.maxStoredResponses, filename)
+			w.storedCounts[filename]++
+		}
+		return false
+	}
+	w.storedCounts[filename]++
+	return true
+}
+
+// syncFile commits the contents of the file to disk
+func syncFile(filename string) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
 }
 
 // WriteFailure writes the failure event for template to file and/or screen.
-func (w *StandardWriter) WriteFailure(event InternalEvent) error {
+func (w *StandardWriter) WriteFailure(event InternalEvent) (err error) {
+	defer w.recoverPanic("WriteFailure", &err)
 	if !w.matcherStatus {
 		return nil
 	}
@@ -509,7 +1979,34 @@ func (w *StandardWriter) WriteFailure(event InternalEvent) error {
 		MatcherStatus: false,
 		Timestamp:     time.Now(),
 	}
-	return w.Write(data)
+	data.FailureType, data.FailureReason = failureReason(event)
+	return w.write(data, noMatchEvent)
+}
+
+// noMatchEvent is the webhook event of a failure event, distinct from
+// the alert event of the matches
+const noMatchEvent = "scan.no_match"
+
+// Types of failures of the failure events
+const (
+	FailureTypeNoMatch = "no-match"
+	FailureTypeError   = "error"
+)
+
+// failureReason returns the type and the reason of the failure from the error of the event
+func failureReason(event InternalEvent) (string, string) {
+	var reason string
+	switch value := event["error"].(type) {
+	case nil:
+	case error:
+		reason = value.Error()
+	default:
+		reason = types.ToString(value)
+	}
+	if reason == "" {
+		return FailureTypeNoMatch, ""
+	}
+	return FailureTypeError, reason
 }
 func sanitizeFileName(fileName string) string {
 	fileName = strings.ReplaceAll(fileName, "http:", "")
@@ -524,21 +2021,47 @@ func sanitizeFileName(fileName string) string {
 	fileName = strings.TrimPrefix(fileName, "__")
 	return fileName
 }
+
+// DefaultStoredFileName names the stored responses <host>_<templateID>.txt
+func DefaultStoredFileName(host, templateID, eventType string) string {
+	return fmt.Sprintf("%s.txt", sanitizeFileName(fmt.Sprintf("%s_%s", host, templateID)))
+}
+
 func (w *StandardWriter) WriteStoreDebugData(host, templateID, eventType string, data string) {
+	defer w.recoverPanic("WriteStoreDebugData", nil)
 	if w.storeResponse {
-		filename := sanitizeFileName(fmt.Sprintf("%s_%s", host, templateID))
-		subFolder := filepath.Join(w.storeResponseDir, sanitizeFileName(eventType))
-		if !fileutil.FolderExists(subFolder) {
-			_ = fileutil.CreateFolder(subFolder)
+		fileNameFunc := w.StoredFileNameFunc
+		if fileNameFunc == nil {
+			fileNameFunc = DefaultStoredFileName
 		}
-		filename = filepath.Join(subFolder, fmt.Sprintf("%s.txt", filename))
-		f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
-		if err != nil {
-			fmt.Print(err)
+		// the name can't escape the folder of the event type
+		filename := filepath.Base(fileNameFunc(host, templateID, eventType))
+		folder := w.storeResponseDir
+		if w.storeDateFolders {
+			// long running scans get a folder per day of findings
+			folder = filepath.Join(folder, w.currentTime().Format("2006-01-02"))
+		}
+		subFolder := filepath.Join(folder, sanitizeFileName(eventType))
+		if err := w.createStoredFolder(subFolder); err != nil {
+			// the error has the path of the folder
+			gologger.Warning().Msgf("Could not store response of %s: %s\n", host, err)
+			return
+		}
+		filename = filepath.Join(subFolder, filename)
+		if !w.reserveStoredResponse(filename) {
 			return
 		}
-		_, _ = f.WriteString(fmt.Sprintln(data))
-		f.Close()
+		if err := w.storedFilePool().write(filename, []byte(normalizeLineEndings(fmt.Sprintln(data), w.lineEndings))); err != nil {
+			gologger.Warning().Msgf("Could not store response of %s: %s\n", host, err)
+			return
+		}
+
+		w.storedFilesMutex.Lock()
+		if w.storedFiles == nil {
+			w.storedFiles = make(map[string]struct{})
+		}
+		w.storedFiles[filename] = struct{}{}
+		w.storedFilesMutex.Unlock()
 	}
 
 }
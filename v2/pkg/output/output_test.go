@@ -1,16 +1,43 @@
 package output
 
 import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/logrusorgru/aurora"
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/gologger/levels"
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/projectdiscovery/nuclei/v2/internal/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/stringslice"
 	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	fileutil "github.com/projectdiscovery/utils/file"
 	"github.com/stretchr/testify/require"
 )
 
 func TestStandardWriterRequest(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
 	t.Run("WithoutTraceAndError", func(t *testing.T) {
 		w, err := NewStandardWriter(&types.Options{})
 		require.NoError(t, err)
@@ -30,7 +57,7 @@ func TestStandardWriterRequest(t *testing.T) {
 		require.NoError(t, err)
 		w.Request("path", "input", "http", nil)
 
-		require.Equal(t, `{"template":"path","input":"input","error":"none","type":"http"}`, traceWriter.String())
+		require.Equal(t, `{"template":"path","input":"input","error":"none","type":"http"}`+"\n", traceWriter.String())
 		require.Empty(t, errorWriter.String())
 	})
 
@@ -47,10 +74,2044 @@ func TestStandardWriterRequest(t *testing.T) {
 			fmt.Errorf("GET https://example.com/tcpconfig.html/tcpconfig.html giving up after 2 attempts: %w", errors.New("context deadline exceeded (Client.Timeout exceeded while awaiting headers)")),
 		)
 
-		require.Equal(t, `{"template":"misconfiguration/tcpconfig.yaml","input":"https://example.com/tcpconfig.html","error":"context deadline exceeded (Client.Timeout exceeded while awaiting headers)","type":"http"}`, errorWriter.String())
+		require.Equal(t, `{"template":"misconfiguration/tcpconfig.yaml","input":"https://example.com/tcpconfig.html","error":"context deadline exceeded (Client.Timeout exceeded while awaiting headers)","category":"timeout","type":"http"}`+"\n", errorWriter.String())
+	})
+}
+
+func TestStandardWriterTraceLog(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	t.Run("JSONL", func(t *testing.T) {
+		dir := t.TempDir()
+		tracePath := filepath.Join(dir, "trace.jsonl")
+		errorPath := filepath.Join(dir, "error.jsonl")
+
+		w, err := NewStandardWriter(&types.Options{TraceLogFile: tracePath, ErrorLogFile: errorPath})
+		require.NoError(t, err)
+		w.Request("first.yaml", "https://example.com", "http", nil)
+		w.Request("second.yaml", "https://example.com", "http", errors.New("connection refused"))
+		w.Close()
+
+		traces := readJSONLogRequests(t, tracePath)
+		require.Len(t, traces, 2)
+		require.Equal(t, "first.yaml", traces[0].Template)
+		require.Equal(t, "none", traces[0].Error)
+		require.Equal(t, "second.yaml", traces[1].Template)
+		require.Equal(t, "connection refused", traces[1].Error)
+
+		errs := readJSONLogRequests(t, errorPath)
+		require.Len(t, errs, 1)
+		require.Equal(t, "second.yaml", errs[0].Template)
+	})
+
+	t.Run("ErrorLogMixedRecords", func(t *testing.T) {
+		errorPath := filepath.Join(t.TempDir(), "error.jsonl")
+
+		w, err := NewStandardWriter(&types.Options{ErrorLogFile: errorPath})
+		require.NoError(t, err)
+		w.Request("first.yaml", "https://example.com", "http", errors.New("connection refused"))
+		w.WriteError("second.yaml", "https://example.com", errors.New("timeout"))
+		w.Close()
+
+		data, err := os.ReadFile(errorPath)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		require.Len(t, lines, 2)
+		for _, line := range lines {
+			require.True(t, json.Valid([]byte(line)), "line %q", line)
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		tracePath := filepath.Join(t.TempDir(), "trace.csv")
+
+		w, err := NewStandardWriter(&types.Options{TraceLogFile: tracePath, TraceLogFormat: TraceFormatCSV})
+		require.NoError(t, err)
+		w.Request("first.yaml", "https://example.com/?a=1,2", "http", nil)
+		w.Request("second.yaml", "https://example.com", "dns", errors.New("timeout"))
+		w.Close()
+
+		file, err := os.Open(tracePath)
+		require.NoError(t, err)
+		defer file.Close()
+		records, err := csv.NewReader(file).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"template", "input", "error", "type"},
+			{"first.yaml", "https://example.com/?a=1,2", "none", "http"},
+			{"second.yaml", "https://example.com", "timeout", "dns"},
+		}, records)
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		_, err := NewStandardWriter(&types.Options{TraceLogFile: filepath.Join(t.TempDir(), "trace"), TraceLogFormat: "xml"})
+		require.Error(t, err)
+	})
+}
+
+// readJSONLogRequests parses a jsonl log file line by line
+func readJSONLogRequests(t *testing.T, path string) []JSONLogRequest {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var requests []JSONLogRequest
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var request JSONLogRequest
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &request), "line %q", scanner.Text())
+		requests = append(requests, request)
+	}
+	require.NoError(t, scanner.Err())
+	return requests
+}
+
+func TestNewStandardWriterResume(t *testing.T) {
+	var events []string
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			events = append(events, "PATCH")
+			return
+		}
+		var body AstraAlertRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		events = append(events, body.Meta.Event)
+	}))
+
+	tests := []struct {
+		name   string
+		resume string
+		events []string
+	}{
+		{"NewScan", "", []string{"PATCH", "scan.started"}},
+		{"ResumedScan", filepath.Join(t.TempDir(), "resume.cfg"), []string{"scan.resumed"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events = nil
+			w, err := NewStandardWriter(&types.Options{Resume: test.resume})
+			require.NoError(t, err)
+			require.Empty(t, events, "scan was started before Start")
+			w.Start()
+			w.Start()
+			require.Equal(t, test.events, events)
+		})
+	}
+
+	t.Run("RequireStatusAPI", func(t *testing.T) {
+		events = nil
+		w, err := NewStandardWriter(&types.Options{Resume: filepath.Join(t.TempDir(), "resume.cfg"), RequireStatusAPI: true})
+		require.NoError(t, err)
+		require.Empty(t, events, "the state of the resumed scan was changed")
+		w.Start()
+		require.Equal(t, []string{"scan.resumed"}, events)
+		require.Equal(t, uint64(0), w.Metrics().StatusChanges)
+	})
+}
+
+func TestStandardWriterScanInfo(t *testing.T) {
+	contexts := make(map[string]json.RawMessage)
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			return
+		}
+		var body AstraAlertRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		contexts[body.Meta.Event] = body.Context
+	}))
+
+	t.Run("WithScanInfo", func(t *testing.T) {
+		w, err := NewStandardWriter(&types.Options{})
+		require.NoError(t, err)
+		w.SetScanInfo(10, 250)
+		w.Start()
+		w.Close()
+
+		var started, complete map[string]interface{}
+		require.NoError(t, json.Unmarshal(contexts["scan.started"], &started))
+		require.Equal(t, "Scan Started successfully", started["reason"])
+		require.Equal(t, float64(10), started["targets"])
+		require.Equal(t, float64(250), started["templates"])
+		require.Contains(t, started, "start-time")
+		require.NotContains(t, started, "end-time")
+
+		require.NoError(t, json.Unmarshal(contexts["scan.complete"], &complete))
+		require.Equal(t, "Scan Completed successfully", complete["reason"])
+		require.Equal(t, float64(10), complete["targets"])
+		require.Equal(t, started["start-time"], complete["start-time"])
+		require.Contains(t, complete, "end-time")
+	})
+
+	t.Run("WithoutScanInfo", func(t *testing.T) {
+		w, err := NewStandardWriter(&types.Options{})
+		require.NoError(t, err)
+		w.Close()
+
+		var started map[string]interface{}
+		require.NoError(t, json.Unmarshal(contexts["scan.started"], &started))
+		require.Equal(t, "Scan Started successfully", started["reason"])
+		require.NotContains(t, started, "targets")
+		require.NotContains(t, started, "templates")
+	})
+}
+
+func TestStandardWriterCloseTwice(t *testing.T) {
+	var mu sync.Mutex
+	var statuses []string
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			return
+		}
+		var body struct {
+			StateChange map[string]string `json:"state_change"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		statuses = append(statuses, body.StateChange["status"])
+		mu.Unlock()
+	}))
+
+	outputDir := t.TempDir()
+	w, err := NewStandardWriter(&types.Options{
+		Output:       filepath.Join(outputDir, "output.txt"),
+		TraceLogFile: filepath.Join(outputDir, "trace.txt"),
+		ErrorLogFile: filepath.Join(outputDir, "error.txt"),
+	})
+	require.NoError(t, err)
+	require.NotPanics(t, func() {
+		w.Close()
+		w.Close()
+	})
+	require.Equal(t, []string{"RUNNING", "COMPLETE"}, statuses, "completion was not sent exactly once")
+}
+
+func TestStandardWriterStatusRequest(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	ts := setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/" {
+			return
+		}
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+	}))
+	serviceName := strings.TrimPrefix(ts.URL, "http://")
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		requests []string
+	}{
+		{"Default", "", "", []string{"PATCH /api/nuclei/scan-id", "PATCH /api/nuclei/scan-id"}},
+		{"Custom", "put", "/v2/{serviceName}/scans/{scanId}/status", []string{
+			"PUT /v2/" + serviceName + "/scans/scan-id/status",
+			"PUT /v2/" + serviceName + "/scans/scan-id/status",
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = nil
+			w, err := NewStandardWriter(&types.Options{StatusMethod: test.method, StatusPathTemplate: test.path})
+			require.NoError(t, err)
+			w.Close()
+			require.Equal(t, test.requests, requests)
+		})
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		tests := []struct {
+			method string
+			path   string
+			err    string
+		}{
+			{"GET", defaultStatusPathTemplate, `invalid status method "GET"`},
+			{"PUT", "api/nuclei/{scanId}", "expected an absolute path"},
+			{"PUT", "/api/nuclei/{scan}", "unknown placeholder {scan}"},
+			{"PUT", "/api/nuclei/{scanId}?status=1", "expected a path without query or fragment"},
+			{"PUT", "/api/nuclei/{scanId", "expected a path without query or fragment"},
+		}
+		for _, test := range tests {
+			_, err := NewStandardWriter(&types.Options{StatusMethod: test.method, StatusPathTemplate: test.path})
+			require.ErrorContains(t, err, test.err)
+		}
+	})
+}
+
+func TestStandardWriterStatusAPIDown(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body AstraAlertRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		events = append(events, body.Meta.Event)
+		mu.Unlock()
+	}))
+	down := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+	t.Setenv("DAST_API_SVC_NAME", strings.TrimPrefix(down.URL, "http://"))
+
+	logs := captureLogs(t)
+	w, err := NewStandardWriter(&types.Options{JSONL: true})
+	require.NoError(t, err, "the writer was not constructed with the status api down")
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+	w.Close()
+	require.Contains(t, logs.String(), "Could not change scan state to running, continuing without the status api")
+	require.Contains(t, logs.String(), "Could not change scan state to complete")
+	require.Equal(t, []string{"scan.started", "alert", "scan.summary", "scan.complete"}, events, "webhook events were not sent")
+	require.Equal(t, uint64(0), w.Metrics().StatusChanges)
+
+	t.Run("Required", func(t *testing.T) {
+		events = nil
+		_, err := NewStandardWriter(&types.Options{RequireStatusAPI: true})
+		require.ErrorContains(t, err, "could not change scan state to running")
+		require.Empty(t, events, "the scan was started without the status api")
+	})
+
+	t.Run("RequiredRejected", func(t *testing.T) {
+		rejecting := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer rejecting.Close()
+		t.Setenv("DAST_API_SVC_NAME", strings.TrimPrefix(rejecting.URL, "http://"))
+
+		_, err := NewStandardWriter(&types.Options{RequireStatusAPI: true})
+		require.ErrorContains(t, err, "status api returned 503 Service Unavailable")
+	})
+
+	t.Run("RequiredUp", func(t *testing.T) {
+		events = nil
+		up := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		defer up.Close()
+		t.Setenv("DAST_API_SVC_NAME", strings.TrimPrefix(up.URL, "http://"))
+
+		w, err := NewStandardWriter(&types.Options{RequireStatusAPI: true})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), w.Metrics().StatusChanges, "the scan state was not changed eagerly")
+		require.Empty(t, events, "scan.started was sent before the scan info was set")
+
+		w.SetScanInfo(10, 20)
+		w.Start()
+		require.Equal(t, []string{"scan.started"}, events)
+		require.Equal(t, uint64(1), w.Metrics().StatusChanges, "the scan state was changed twice")
+	})
+}
+
+func TestStandardWriterStatusTLS(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	var mu sync.Mutex
+	var requests []string
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+	}))
+	defer tlsServer.Close()
+	t.Setenv("DAST_API_SVC_NAME", strings.TrimPrefix(tlsServer.URL, "https://"))
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundle, certificate, 0644))
+
+	tests := []struct {
+		name    string
+		options *types.Options
+	}{
+		{"CABundle", &types.Options{StatusScheme: "https", StatusCABundle: caBundle}},
+		{"InsecureSkipVerify", &types.Options{StatusScheme: "https", StatusInsecureSkipVerify: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests = nil
+			w, err := NewStandardWriter(test.options)
+			require.NoError(t, err)
+			w.Start()
+			require.Equal(t, []string{"PATCH /api/nuclei/scan-id"}, requests)
+		})
+	}
+
+	t.Run("UntrustedCertificate", func(t *testing.T) {
+		w, err := NewStandardWriter(&types.Options{StatusScheme: "https"})
+		require.NoError(t, err)
+		_, err = w.statusClient.Get(tlsServer.URL)
+		require.ErrorContains(t, err, "certificate")
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := NewStandardWriter(&types.Options{StatusScheme: "ftp"})
+		require.ErrorContains(t, err, `invalid status scheme "ftp"`)
+
+		_, err = NewStandardWriter(&types.Options{StatusCABundle: filepath.Join(t.TempDir(), "missing.pem")})
+		require.ErrorContains(t, err, "could not read status api ca bundle")
+
+		invalidBundle := filepath.Join(t.TempDir(), "invalid.pem")
+		require.NoError(t, os.WriteFile(invalidBundle, []byte("not a certificate"), 0644))
+		_, err = NewStandardWriter(&types.Options{StatusCABundle: invalidBundle})
+		require.ErrorContains(t, err, "no pem certificates found")
+	})
+}
+
+func TestStandardWriterLogVerbosity(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name     string
+		options  *types.Options
+		scan     bool
+		perEvent bool
+	}{
+		{"Default", &types.Options{}, true, false},
+		{"Verbose", &types.Options{Verbose: true}, true, true},
+		{"Silent", &types.Options{Verbose: true, Silent: true}, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			w, err := NewStandardWriter(test.options)
+			require.NoError(t, err)
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", TemplateURL: "https://templates.example.com/test"}))
+			w.Close()
+
+			output := logs.String()
+			for _, message := range []string{"Changing scan state to running", "Sending status change request with action -> COMPLETE", "triggering complete event"} {
+				require.Equal(t, test.scan, strings.Contains(output, message), message)
+			}
+			for _, message := range []string{"Raising alert for ->", "Request status received ->", "Status code received for `status change api`"} {
+				require.Equal(t, test.perEvent, strings.Contains(output, message), message)
+			}
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		logs := captureLogs(t)
+		w := newTestWriter("http://127.0.0.1:0")
+		w.silent = true
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		require.Contains(t, logs.String(), "Could not send alert", "errors should be logged when silent")
+	})
+}
+
+func TestNewStandardWriterValidation(t *testing.T) {
+	ts := setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	serviceName := strings.TrimPrefix(ts.URL, "http://")
+
+	tests := []struct {
+		name        string
+		webhookURL  string
+		serviceName string
+		err         string
+	}{
+		{name: "Valid", webhookURL: ts.URL + "/webhook", serviceName: serviceName},
+		{name: "EmptyWebhookURL", webhookURL: "", serviceName: serviceName, err: "webhook url is empty"},
+		{name: "RelativeWebhookURL", webhookURL: "/api/webhook", serviceName: serviceName, err: "expected an absolute http or https url"},
+		{name: "NonHTTPWebhookURL", webhookURL: "ftp://example.com/webhook", serviceName: serviceName, err: "expected an absolute http or https url"},
+		{name: "ServiceNameWithScheme", webhookURL: ts.URL, serviceName: "http://dast-api:8080", err: "invalid api service name"},
+		{name: "ServiceNameWithInvalidPort", webhookURL: ts.URL, serviceName: "dast-api:port", err: "invalid api service name"},
+		{name: "EmptyServiceName", webhookURL: ts.URL, serviceName: "", err: "api service name is empty"},
+		{name: "WebhookURLPlaceholder", webhookURL: ts.URL + "/${NUCLEI_TEST_REGION}/webhook", serviceName: serviceName},
+		{name: "ServiceNamePlaceholder", webhookURL: ts.URL, serviceName: "${NUCLEI_TEST_SERVICE}"},
+		{name: "UnsetWebhookURLPlaceholder", webhookURL: ts.URL + "/${NUCLEI_TEST_UNSET}/webhook", serviceName: serviceName, err: "could not expand webhook url: environment variables not set: NUCLEI_TEST_UNSET"},
+		{name: "UnsetServiceNamePlaceholder", webhookURL: ts.URL, serviceName: "${NUCLEI_TEST_UNSET}", err: "could not expand api service name: environment variables not set: NUCLEI_TEST_UNSET"},
+	}
+	t.Setenv("NUCLEI_TEST_REGION", "eu-west-1")
+	t.Setenv("NUCLEI_TEST_SERVICE", serviceName)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("webhookUrl", test.webhookURL)
+			t.Setenv("DAST_API_SVC_NAME", test.serviceName)
+
+			w, err := NewStandardWriter(&types.Options{})
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			w.Close()
+		})
+	}
+
+	t.Run("ExpandEnvPlaceholders", func(t *testing.T) {
+		expanded, err := expandEnvPlaceholders("https://hooks.example.com/${NUCLEI_TEST_REGION}/$HOME/{scan}")
+		require.NoError(t, err)
+		require.Equal(t, "https://hooks.example.com/eu-west-1/$HOME/{scan}", expanded, "only ${VAR} placeholders should be expanded")
+	})
+
+	t.Run("SchemeLessServiceName", func(t *testing.T) {
+		require.NoError(t, validateApiServiceName("dast-api", "http", defaultStatusPathTemplate, "scan-id"))
+		require.NoError(t, validateApiServiceName("dast-api.default.svc:8080", "http", defaultStatusPathTemplate, "scan-id"))
+	})
+}
+
+func TestStandardWriterWriteError(t *testing.T) {
+	t.Run("ErrorFileOnly", func(t *testing.T) {
+		traceWriter := &testWriteCloser{}
+		errorWriter := &testWriteCloser{}
+
+		w := &StandardWriter{traceFile: traceWriter, errorFile: errorWriter}
+		w.WriteError("tcpconfig", "https://example.com", fmt.Errorf("could not connect: %w", errors.New("connection refused")))
+
+		require.Equal(t, `{"template":"tcpconfig","input":"https://example.com","error":"connection refused","error-type":"*errors.fundamental"}`+"\n", errorWriter.String())
+		require.Empty(t, traceWriter.String())
+	})
+
+	t.Run("NilError", func(t *testing.T) {
+		errorWriter := &testWriteCloser{}
+
+		w := &StandardWriter{errorFile: errorWriter}
+		w.WriteError("tcpconfig", "https://example.com", nil)
+
+		require.Empty(t, errorWriter.String())
+	})
+
+	t.Run("ForwardToWebhook", func(t *testing.T) {
+		var received AstraAlertRequest
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&received)
+		}))
+		defer ts.Close()
+
+		w := newTestWriter(ts.URL)
+		w.webhookErrors = true
+		w.WriteError("tcpconfig", "https://example.com", errors.New("connection refused"))
+
+		require.Equal(t, "scan.error", received.Meta.Event)
+		require.JSONEq(t, `{"template":"tcpconfig","input":"https://example.com","error":"connection refused","error-type":"*errors.fundamental"}`, string(received.Context))
+	})
+}
+
+func TestStandardWriterWriteInteraction(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	rawRequest := "\x9a\x01\x01\x00\x00\x01\x00\x00\x00\x00\x00\x01\x05oast\x02me\x00"
+	interaction := &server.Interaction{
+		Protocol:      "dns",
+		UniqueID:      "c8rlrmhp4ttg0b1g",
+		QType:         "A",
+		RawRequest:    rawRequest,
+		RawResponse:   ";; opcode: QUERY, status: NOERROR",
+		RemoteAddress: "172.253.226.100",
+	}
+
+	w := newTestWriter(ts.URL)
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "blind-ssrf", Host: "https://example.com", Interaction: interaction}))
+
+	var event struct {
+		Interaction *server.Interaction `json:"interaction"`
+	}
+	require.NoError(t, json.Unmarshal(received.Context, &event))
+	require.NotNil(t, event.Interaction, "interaction not present in alert")
+	require.Equal(t, "dns", event.Interaction.Protocol)
+	require.Equal(t, "172.253.226.100", event.Interaction.RemoteAddress)
+
+	decodedRequest, err := base64.StdEncoding.DecodeString(event.Interaction.RawRequest)
+	require.NoError(t, err)
+	require.Equal(t, rawRequest, string(decodedRequest))
+	decodedResponse, err := base64.StdEncoding.DecodeString(event.Interaction.RawResponse)
+	require.NoError(t, err)
+	require.Equal(t, ";; opcode: QUERY, status: NOERROR", string(decodedResponse))
+
+	require.Equal(t, rawRequest, interaction.RawRequest, "shared interaction was modified")
+}
+
+func TestStandardWriterWriteClassification(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+
+	t.Run("FullClassification", func(t *testing.T) {
+		info := model.Info{
+			Name: "Apache Struts RCE",
+			Classification: &model.Classification{
+				CVEID:       stringslice.StringSlice{Value: []string{"CVE-2017-5638"}},
+				CWEID:       stringslice.StringSlice{Value: "CWE-20"},
+				CVSSMetrics: "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+				CVSSScore:   10,
+			},
+		}
+		event := &ResultEvent{TemplateID: "CVE-2017-5638", Info: info}
+		require.NoError(t, w.Write(event))
+		require.Equal(t, []string{"CVE-2017-5638"}, event.CVE)
+		require.Equal(t, []string{"CWE-20"}, event.CWE)
+		require.Equal(t, float64(10), event.CVSSScore)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(received.Context, &fields))
+		require.Equal(t, []interface{}{"CVE-2017-5638"}, fields["cve"])
+		require.Equal(t, []interface{}{"CWE-20"}, fields["cwe"])
+		require.Equal(t, float64(10), fields["cvss-score"])
+		require.Contains(t, fields["info"], "classification", "inline classification was removed")
+	})
+
+	t.Run("NoClassification", func(t *testing.T) {
+		event := &ResultEvent{TemplateID: "tech-detect", Info: model.Info{Name: "Tech Detect"}}
+		require.NoError(t, w.Write(event))
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(received.Context, &fields))
+		require.NotContains(t, fields, "cve")
+		require.NotContains(t, fields, "cwe")
+		require.NotContains(t, fields, "cvss-score")
 	})
 }
 
+func TestStandardWriterExtractedValues(t *testing.T) {
+	var received struct {
+		Context map[string]interface{} `json:"context"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	w.extractedValues = true
+
+	tests := []struct {
+		name          string
+		extractorName string
+		results       []string
+		expected      interface{}
+	}{
+		{"Named", "version", []string{"2.4.49"}, map[string]interface{}{"version": "2.4.49"}},
+		{"NamedSeveral", "emails", []string{"a@example.com", "b@example.com"}, map[string]interface{}{"emails[0]": "a@example.com", "emails[1]": "b@example.com"}},
+		{"Unnamed", "", []string{"2.4.49"}, nil},
+		{"NoResults", "version", nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			received.Context = nil
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "apache-version", ExtractorName: test.extractorName, ExtractedResults: test.results}))
+			require.Equal(t, test.expected, received.Context["extracted-values"])
+			if len(test.results) > 0 {
+				require.Len(t, received.Context["extracted-results"], len(test.results), "the list of results is kept")
+			}
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "apache-version", ExtractorName: "version", ExtractedResults: []string{"2.4.49"}}))
+		require.NotContains(t, received.Context, "extracted-values")
+	})
+}
+
+func TestStandardWriterWriteAuthorsAndTags(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+
+	t.Run("AuthorsAndTags", func(t *testing.T) {
+		info := model.Info{
+			Name:    "Apache Struts RCE",
+			Authors: stringslice.StringSlice{Value: []string{"pdteam", " ", "geeknik"}},
+			Tags:    stringslice.StringSlice{Value: "cve"},
+		}
+		event := &ResultEvent{TemplateID: "CVE-2017-5638", Info: info}
+		require.NoError(t, w.Write(event))
+		require.Equal(t, []string{"pdteam", "geeknik"}, event.Authors)
+		require.Equal(t, []string{"cve"}, event.Tags)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(received.Context, &fields))
+		require.Equal(t, []interface{}{"pdteam", "geeknik"}, fields["authors"])
+		require.Equal(t, []interface{}{"cve"}, fields["tags"])
+	})
+
+	t.Run("NoAuthorsAndTags", func(t *testing.T) {
+		event := &ResultEvent{TemplateID: "tech-detect", Info: model.Info{Name: "Tech Detect", Tags: stringslice.StringSlice{Value: ""}}}
+		require.NoError(t, w.Write(event))
+		require.Nil(t, event.Authors)
+		require.Nil(t, event.Tags)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(received.Context, &fields))
+		require.NotContains(t, fields, "authors")
+		require.NotContains(t, fields, "tags")
+	})
+}
+
+func TestStandardWriterWriteNormalizesSeverity(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		severity severity.Severity
+		expected severity.Severity
+	}{
+		{"Empty", severity.Undefined, severity.Unknown},
+		{"Bogus", severity.Severity(42), severity.Unknown},
+		{"Negative", severity.Severity(-1), severity.Unknown},
+		{"Valid", severity.High, severity.High},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := newTestWriter(ts.URL)
+			event := &ResultEvent{TemplateID: "test", Info: model.Info{SeverityHolder: severity.Holder{Severity: test.severity}}}
+			require.NoError(t, w.Write(event))
+			require.Equal(t, test.expected, event.Info.SeverityHolder.Severity)
+			require.Equal(t, map[severity.Severity]int{test.expected: 1}, w.Counts())
+
+			var fields struct {
+				Info struct {
+					Severity string `json:"severity"`
+				} `json:"info"`
+			}
+			require.NoError(t, json.Unmarshal(received.Context, &fields))
+			require.Equal(t, test.expected.String(), fields.Info.Severity)
+		})
+	}
+
+	t.Run("Screen", func(t *testing.T) {
+		outputWriter := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.json = false
+		w.outputFile = outputWriter
+		event := &ResultEvent{TemplateID: "test", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Severity(42)}}}
+		require.NoError(t, w.Write(event))
+		require.Contains(t, outputWriter.String(), "[unknown]")
+	})
+}
+
+func TestNormalizeMatchedAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		matched  string
+		expected string
+	}{
+		{"MixedCaseHost", "HTTPS://Example.COM/Admin", "https://example.com/Admin"},
+		{"TrailingSlash", "https://example.com/admin/", "https://example.com/admin"},
+		{"RootTrailingSlash", "https://example.com/", "https://example.com"},
+		{"QueryParams", "https://example.com/search?q=1&page=2#results", "https://example.com/search"},
+		{"RelativePath", "https://example.com/a/./b/../c//d", "https://example.com/a/c/d"},
+		{"Port", "http://Example.com:8080/login?next=/", "http://example.com:8080/login"},
+		{"NotURL", "Example.com:22", "Example.com:22"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, normalizeMatchedAt(test.matched))
+		})
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		w := newTestWriter(ts.URL)
+		w.normalizeMatchedAt = true
+		event := &ResultEvent{TemplateID: "exposed-panel", Matched: "https://Example.com/admin/?token=1"}
+		require.NoError(t, w.Write(event))
+		require.Equal(t, "https://example.com/admin", event.Matched)
+	})
+}
+
+func TestParseMatchedAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		matched string
+		scheme  string
+		port    int
+		path    string
+	}{
+		{"ExplicitPort", "https://example.com:8443/api/login?next=/", "https", 8443, "/api/login"},
+		{"DefaultPort", "http://example.com/admin", "http", 80, "/admin"},
+		{"DefaultHTTPSPort", "HTTPS://example.com", "https", 443, "/"},
+		{"EscapedPath", "https://example.com/a%20b", "https", 443, "/a%20b"},
+		{"NotURL", "example.com:22", "", 0, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event := &ResultEvent{Matched: test.matched}
+			parseMatchedAt(event)
+			require.Equal(t, test.matched, event.Matched, "matched-at was modified")
+			require.Equal(t, test.scheme, event.MatchedScheme)
+			require.Equal(t, test.port, event.MatchedPort)
+			require.Equal(t, test.path, event.MatchedPath)
+		})
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		outputFile := &testWriteCloser{}
+		w := newTestWriter("")
+		w.outputFile = outputFile
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "exposed-panel", Type: "http", Matched: "https://example.com:8443/admin"}))
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+		require.Equal(t, "https", fields["matched-scheme"])
+		require.Equal(t, float64(8443), fields["matched-port"])
+		require.Equal(t, "/admin", fields["matched-path"])
+
+		event := &ResultEvent{TemplateID: "dns-txt", Type: "dns", Matched: "https://example.com/admin"}
+		require.NoError(t, w.Write(event))
+		require.Empty(t, event.MatchedScheme, "non http results were parsed")
+	})
+}
+
+func TestStandardWriterOnResult(t *testing.T) {
+	var alerts int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) { alerts++ }))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+
+	t.Run("EnrichedEvent", func(t *testing.T) {
+		var received *ResultEvent
+		w.OnResult = func(event *ResultEvent) {
+			require.Equal(t, 0, alerts, "callback invoked after the webhook delivery")
+			received = event
+		}
+		info := model.Info{Classification: &model.Classification{CVEID: stringslice.StringSlice{Value: "CVE-2021-44228"}}}
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Info: info}))
+		require.NotNil(t, received, "callback was not invoked")
+		require.False(t, received.Timestamp.IsZero(), "event was not enriched")
+		require.Equal(t, []string{"CVE-2021-44228"}, received.CVE)
+		require.Equal(t, 1, alerts)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		logs := captureLogs(t)
+		w.OnResult = func(event *ResultEvent) { panic("callback failed") }
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		require.Equal(t, 2, alerts, "alert was not delivered after callback panic")
+		require.Contains(t, logs.String(), "callback failed")
+	})
+}
+
+func TestStandardWriterFlush(t *testing.T) {
+	var mu sync.Mutex
+	var delivered int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	outputPath := filepath.Join(t.TempDir(), "output.jsonl")
+	outputFile, err := newFileOutputWriter(outputPath, false)
+	require.NoError(t, err)
+
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	w.storeResponse = true
+	w.storeResponseDir = t.TempDir()
+	w.alertQueue = newAlertQueue(10, 1, DropPolicyBlock, w.deliverAlert)
+	w.drainTimeout = 5 * time.Second
+	defer w.alertQueue.close(time.Second)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i)}))
+	}
+	w.WriteStoreDebugData("example.com", "test", "http", "data")
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Equal(t, 5, strings.Count(string(data), "\n"), "results were held back until the flush")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, w.Flush())
+		}()
+	}
+	wg.Wait()
+
+	data, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Equal(t, 5, strings.Count(string(data), "\n"), "buffered output was not flushed")
+	mu.Lock()
+	require.Equal(t, 5, delivered, "queued alerts were not delivered")
+	mu.Unlock()
+	require.Empty(t, w.storedFiles, "stored responses were not synced")
+
+	// the writer keeps working after a flush
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "template-5"}))
+	require.NoError(t, w.Flush())
+	mu.Lock()
+	require.Equal(t, 6, delivered)
+	mu.Unlock()
+}
+
+func TestStandardWriterSeverityOverrides(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	w.severityOverrides = map[string]severity.Severity{"tech-detect": severity.Low}
+
+	tests := []struct {
+		templateID string
+		expected   string
+	}{
+		{"tech-detect", "low"},
+		{"waf-detect", "info"},
+	}
+	for _, test := range tests {
+		t.Run(test.templateID, func(t *testing.T) {
+			info := model.Info{SeverityHolder: severity.Holder{Severity: severity.Info}}
+			event := &ResultEvent{TemplateID: test.templateID, Info: info}
+			require.NoError(t, w.Write(event))
+			require.Equal(t, test.expected, event.Info.SeverityHolder.Severity.String())
+
+			var fields struct {
+				Info struct {
+					Severity string `json:"severity"`
+				} `json:"info"`
+			}
+			require.NoError(t, json.Unmarshal(received.Context, &fields))
+			require.Equal(t, test.expected, fields.Info.Severity)
+		})
+	}
+}
+
+func TestStandardWriterTemplateSource(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	source := "id: git-config\n\ninfo:\n  name: Git Config Disclosure\n  severity: medium\n"
+	templatePath := filepath.Join(t.TempDir(), "git-config.yaml")
+	require.NoError(t, os.WriteFile(templatePath, []byte(source), 0644))
+
+	w := newTestWriter(ts.URL)
+	w.templateSource = true
+
+	templateSource := func() interface{} {
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(received.Context, &fields))
+		return fields["template-source"]
+	}
+
+	t.Run("Present", func(t *testing.T) {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", TemplatePath: templatePath}))
+		require.Equal(t, source, templateSource())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		w.templateSource = true
+		w.templateSourceLimit = 14
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", TemplatePath: templatePath}))
+		require.Equal(t, "id: git-config", templateSource())
+	})
+
+	t.Run("Absent", func(t *testing.T) {
+		missingPath := filepath.Join(t.TempDir(), "missing.yaml")
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "missing", TemplatePath: missingPath}))
+		require.Nil(t, templateSource())
+		require.Contains(t, string(received.Context), `"template-id":"missing"`)
+	})
+}
+
+func TestStandardWriterWriteFailure(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		received = AstraAlertRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	w.matcherStatus = true
+
+	tests := []struct {
+		name     string
+		err      interface{}
+		expected string
+	}{
+		{"NoMatch", nil, `"failure-type":"no-match"`},
+		{"Error", errors.New("connection refused"), `"failure-type":"error","failure-reason":"connection refused"`},
+		{"ErrorString", "i/o timeout", `"failure-type":"error","failure-reason":"i/o timeout"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event := InternalEvent{"template-id": "tech-detect", "host": "https://example.com", "type": "http"}
+			if test.err != nil {
+				event["error"] = test.err
+			}
+			require.NoError(t, w.WriteFailure(event))
+			require.Contains(t, string(received.Context), test.expected)
+			if test.err == nil {
+				require.NotContains(t, string(received.Context), "failure-reason")
+			}
+		})
+	}
+
+	t.Run("EventName", func(t *testing.T) {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "tech-detect", MatcherStatus: true}))
+		require.Equal(t, "alert", received.Meta.Event)
+		require.Contains(t, string(received.Context), `"matcher-status":true`)
+
+		require.NoError(t, w.WriteFailure(InternalEvent{"template-id": "tech-detect", "host": "https://example.com"}))
+		require.Equal(t, "scan.no_match", received.Meta.Event)
+		require.Contains(t, string(received.Context), `"matcher-status":false`)
+	})
+
+	t.Run("NotCounted", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		w.matcherStatus = true
+		info := model.Info{SeverityHolder: severity.Holder{Severity: severity.Critical}}
+		require.NoError(t, w.WriteFailure(InternalEvent{"template-id": "tech-detect", "host": "https://example.com", "template-info": info}))
+		require.Empty(t, w.Counts(), "the failure was counted as a finding")
+		require.False(t, w.HasSeverityAtLeast(severity.Info))
+		require.Empty(t, w.templateCounts.top(maxSummaryTemplates))
+
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "tech-detect", Info: info}))
+		require.Equal(t, map[severity.Severity]int{severity.Critical: 1}, w.Counts())
+		require.Equal(t, []templateCount{{TemplateID: "tech-detect", Count: 1}}, w.templateCounts.top(maxSummaryTemplates))
+	})
+
+	t.Run("MatcherStatusDisabled", func(t *testing.T) {
+		received = AstraAlertRequest{}
+		w := newTestWriter(ts.URL)
+		require.NoError(t, w.WriteFailure(InternalEvent{"template-id": "tech-detect", "error": "i/o timeout"}))
+		require.Empty(t, received.Meta.Event, "failure was sent without matcher status")
+	})
+}
+
+func TestStandardWriterRecoversPanics(t *testing.T) {
+	var mu sync.Mutex
+	var alerts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var received AstraAlertRequest
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		mu.Lock()
+		alerts = append(alerts, string(received.Context))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+	captureLogs(t)
+
+	t.Run("Write", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		w.json = false
+		w.severityColors = func(severity.Severity) string { panic("broken colorizer") }
+
+		err := w.Write(&ResultEvent{TemplateID: "panicking"})
+		require.ErrorContains(t, err, "recovered from panic in Write: broken colorizer")
+		require.Equal(t, uint64(1), w.Metrics().Panics)
+
+		// the scan continues with the next result
+		w.json = true
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "next"}))
+		require.Contains(t, alerts[len(alerts)-1], `"template-id":"next"`)
+	})
+
+	t.Run("WriteFailure", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		w.matcherStatus = true
+
+		err := w.WriteFailure(InternalEvent{"template-id": "tech-detect", "template-info": "not an info"})
+		require.ErrorContains(t, err, "recovered from panic in WriteFailure")
+		require.Equal(t, uint64(1), w.Metrics().Panics)
+		require.NoError(t, w.WriteFailure(InternalEvent{"template-id": "tech-detect"}))
+	})
+
+	t.Run("WriteStoreDebugData", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		w.storeResponse = true
+		w.storeResponseDir = t.TempDir()
+		w.StoredFileNameFunc = func(host, templateID, eventType string) string { panic("broken file name") }
+
+		require.NotPanics(t, func() {
+			w.WriteStoreDebugData("https://example.com", "git-config", "http", "data")
+		})
+		require.Equal(t, uint64(1), w.Metrics().Panics)
+	})
+}
+
+func TestNewStandardWriterColorTheme(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	w, err := NewStandardWriter(&types.Options{ColorTheme: colorizer.ThemeHighContrast})
+	require.NoError(t, err)
+	require.Equal(t, "\x1b[1;97;41mcritical\x1b[0m", w.severityColors(severity.Critical))
+
+	w, err = NewStandardWriter(&types.Options{ColorTheme: colorizer.ThemeHighContrast, NoColor: true})
+	require.NoError(t, err)
+	require.Equal(t, "critical", w.severityColors(severity.Critical), "no color didn't force the monochrome theme")
+
+	_, err = NewStandardWriter(&types.Options{ColorTheme: "solarized"})
+	require.ErrorContains(t, err, "invalid color theme")
+}
+
+func TestStandardWriterSchemaVersion(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(received.Context, &fields))
+	require.Equal(t, ResultSchemaVersion, fields["schema_version"])
+}
+
+func TestStandardWriterWriteRaw(t *testing.T) {
+	var received []byte
+	var idempotencyKeys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		idempotencyKeys = append(idempotencyKeys, r.Header.Get("Idempotency-Key"))
+	}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	w.AstraMeta = AstraMeta{AuditId: "audit-id", ScanId: "scan-id"}
+
+	raw := []byte(`{"template-id":"external","template-url":"https://templates.example.com/external","custom":{"nested":[1,2,3]}}`)
+	require.NoError(t, w.WriteRaw(raw))
+	require.Equal(t, string(raw), outputFile.String(), "raw event was not written unchanged")
+
+	var request struct {
+		Meta    AstraMeta       `json:"meta"`
+		Context json.RawMessage `json:"context"`
+	}
+	require.NoError(t, json.Unmarshal(received, &request))
+	require.Equal(t, "alert", request.Meta.Event)
+	require.Equal(t, "scan-id", request.Meta.ScanId)
+	require.Equal(t, string(raw), string(request.Context), "raw event was not delivered unchanged")
+	require.Equal(t, idempotencyKeys[0], request.Meta.IdempotencyKey)
+	require.NotEmpty(t, idempotencyKeys[0])
+
+	err := w.WriteRaw([]byte(`{"template-id":`))
+	require.EqualError(t, err, "could not write raw event: invalid json")
+	require.Len(t, idempotencyKeys, 1, "invalid json was delivered")
+}
+
+func TestStandardWriterSequenceNumbers(t *testing.T) {
+	var mu sync.Mutex
+	var alerted []uint64
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var received struct {
+			Context struct {
+				Seq uint64 `json:"seq"`
+			} `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		mu.Lock()
+		alerted = append(alerted, received.Context.Seq)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	outputPath := filepath.Join(t.TempDir(), "output.jsonl")
+	outputWriter, err := newFileOutputWriter(outputPath, false)
+	require.NoError(t, err)
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputWriter
+
+	const writers, results = 8, 25
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for j := 0; j < results; j++ {
+				require.NoError(t, w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d-%d", writer, j)}))
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, outputWriter.Close())
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, writers*results)
+	for i, line := range lines {
+		var fields struct {
+			Seq uint64 `json:"seq"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &fields))
+		require.Equal(t, uint64(i+1), fields.Seq, "output lines are not in sequence order")
+	}
+
+	require.Len(t, alerted, writers*results)
+	seen := make(map[uint64]struct{})
+	for _, seq := range alerted {
+		require.NotContains(t, seen, seq, "duplicate sequence number")
+		require.True(t, seq >= 1 && seq <= writers*results)
+		seen[seq] = struct{}{}
+	}
+}
+
+func TestStandardWriterScanIDs(t *testing.T) {
+	var received AstraAlertRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer ts.Close()
+
+	outputWriter := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputWriter
+	w.AstraMeta = AstraMeta{AuditId: "audit-id", ScanId: "scan-id", JobId: "job-id"}
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+
+	for _, data := range []string{outputWriter.String(), string(received.Context)} {
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(data), &fields))
+		require.Equal(t, "audit-id", fields["audit-id"])
+		require.Equal(t, "scan-id", fields["scan-id"])
+		require.Equal(t, "job-id", fields["job-id"])
+	}
+
+	t.Run("NoMeta", func(t *testing.T) {
+		w := newTestWriter(ts.URL)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.NotContains(t, string(received.Context), "scan-id")
+	})
+}
+
+func TestStandardWriterStdout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	t.Run("JSON", func(t *testing.T) {
+		stdout := &strings.Builder{}
+		w := newTestWriter(ts.URL)
+		w.stdout = stdout
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "first"}))
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "second"}))
+
+		lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+		for i, templateID := range []string{"first", "second"} {
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(lines[i]), &fields))
+			require.Equal(t, templateID, fields["template-id"])
+		}
+	})
+
+	t.Run("Screen", func(t *testing.T) {
+		stdout := &strings.Builder{}
+		w := newTestWriter(ts.URL)
+		w.json = false
+		w.aurora = aurora.NewAurora(true)
+		w.severityColors = colorizer.New(w.aurora)
+		w.stdout = stdout
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Type: "http", Matched: "https://example.com/.git/config", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Medium}}}))
+
+		require.Equal(t, "[git-config] [http] [medium] https://example.com/.git/config\n", stdout.String())
+	})
+
+	t.Run("Option", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		reader, writer, err := os.Pipe()
+		require.NoError(t, err)
+		defer reader.Close()
+		stdout := os.Stdout
+		os.Stdout = writer
+		defer func() { os.Stdout = stdout }()
+
+		w, err := NewStandardWriter(&types.Options{Stdout: true, JSONL: true})
+		require.NoError(t, err)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "piped"}))
+		writer.Close()
+		os.Stdout = stdout
+		w.Close()
+
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"template-id":"piped"`)
+		require.True(t, json.Valid(data))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		w, err := NewStandardWriter(&types.Options{})
+		require.NoError(t, err)
+		require.Nil(t, w.stdout)
+	})
+}
+
+func TestResultEventLatency(t *testing.T) {
+	w := newTestWriter("")
+
+	event := &ResultEvent{TemplateID: "test", Host: "https://example.com", Latency: 1250}
+	data, err := w.formatJSON(event)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"latency-ms":1250`)
+
+	var decoded struct {
+		Latency int64 `json:"latency-ms"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, event.Latency, decoded.Latency)
+
+	require.Contains(t, string(w.formatScreen(event)), " [1250ms]")
+
+	data, err = w.formatJSON(&ResultEvent{TemplateID: "test"})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "latency-ms", "unset latency should be omitted")
+}
+
+func TestStandardWriterOutputDiskFull(t *testing.T) {
+	var delivered int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		delivered++
+	}))
+	defer ts.Close()
+
+	t.Run("Error", func(t *testing.T) {
+		delivered = 0
+		w := newTestWriter(ts.URL)
+		w.outputFile = &failingWriteCloser{err: syscall.ENOSPC}
+
+		err := w.Write(&ResultEvent{TemplateID: "test"})
+		require.ErrorIs(t, err, syscall.ENOSPC)
+		require.ErrorContains(t, err, "could not write to output")
+		require.Equal(t, 0, delivered)
+	})
+
+	t.Run("Tolerated", func(t *testing.T) {
+		delivered = 0
+		logs := captureLogs(t)
+		w := newTestWriter(ts.URL)
+		w.outputFile = &failingWriteCloser{err: syscall.ENOSPC}
+		w.tolerateOutputErr = true
+
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		require.Equal(t, 2, delivered, "results were not delivered to the webhook")
+		require.Equal(t, 1, strings.Count(logs.String(), "Could not write to output"), "write error was not logged once")
+		require.Contains(t, logs.String(), syscall.ENOSPC.Error())
+	})
+}
+
+// failingWriteCloser is an output file failing all the writes with err
+type failingWriteCloser struct {
+	err error
+}
+
+func (w *failingWriteCloser) Write(data []byte) (int, error) {
+	return 0, w.err
+}
+
+func (w *failingWriteCloser) Close() error {
+	return nil
+}
+
+func TestStandardWriterRawResponse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	rawResponse := "HTTP/1.1 200 OK\r\nX-First: 1\r\nContent-Type: text/html\r\n\r\n<html>proof</html>"
+	tests := []struct {
+		name        string
+		rawResponse bool
+	}{
+		{"Enabled", true},
+		{"Disabled", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputFile := &testWriteCloser{}
+			w := newTestWriter(ts.URL)
+			w.jsonReqResp = true
+			w.outputFile = outputFile
+			w.rawResponse = test.rawResponse
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Response: rawResponse}))
+
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+
+			reconstructed, err := base64.StdEncoding.DecodeString(fields["response"].(string))
+			require.NoError(t, err)
+			require.Contains(t, string(reconstructed), "Status code: 200")
+			require.NotContains(t, string(reconstructed), "<html>proof</html>")
+
+			if !test.rawResponse {
+				require.NotContains(t, fields, "raw-response")
+				return
+			}
+			raw, err := base64.StdEncoding.DecodeString(fields["raw-response"].(string))
+			require.NoError(t, err)
+			require.Equal(t, rawResponse, string(raw))
+		})
+	}
+}
+
+func TestExtractResponseBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected string
+	}{
+		{"Plain", "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\n\r\nhello world", "hello world"},
+		{"Chunked", "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n6\r\n world\r\n0\r\n\r\n", "hello world"},
+		{"ChunkedMixedCase", "HTTP/1.1 200 OK\r\nTransfer-Encoding: gzip, Chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n", "hello"},
+		{"MalformedChunked", "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\nzz\r\nhello\r\n", "zz\r\nhello\r\n"},
+		{"TruncatedChunked", "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n10\r\nhello", "10\r\nhello"},
+		{"NoBody", "HTTP/1.1 204 No Content\r\nServer: test", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, headers := extractResponseData(test.response)
+			require.Equal(t, test.expected, extractResponseBody(test.response, headers))
+		})
+	}
+
+	t.Run("Reconstructed", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.jsonReqResp = true
+		w.responseBody = true
+		w.outputFile = outputFile
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Response: tests[1].response}))
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+		response, err := base64.StdEncoding.DecodeString(fields["response"].(string))
+		require.NoError(t, err)
+		require.Equal(t, "HTTP version: 1.1\nStatus code: 200\ntransfer-encoding: chunked\n\nhello world", string(response))
+	})
+}
+
+func TestExtractResponseData(t *testing.T) {
+	body := strings.Repeat("<p>body</p>\n", 1000)
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{"CRLF", "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nSet-Cookie:  id=1; Path=/\r\n\r\n" + body},
+		{"LF", "HTTP/1.0 404 Not Found\nServer: nginx\nContent-Length: 0\n\n"},
+		{"NoReason", "HTTP/1.1 204\r\nServer: test\r\n\r\n"},
+		{"NoStatusLine", "Server: test\r\nX-Powered-By: php\r\n\r\n" + body},
+		{"DuplicateHeader", "HTTP/1.1 302 Found\r\nLocation: /a\r\nlocation: /b\r\n\r\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpVersion, statusCode, headers := extractResponseData(test.response)
+			expectedVersion, expectedCode, expectedHeaders := regexpResponseData(test.response)
+			require.Equal(t, expectedVersion, httpVersion)
+			require.Equal(t, expectedCode, statusCode)
+			require.Equal(t, expectedHeaders, headers)
+		})
+	}
+
+	t.Run("BodyNotParsed", func(t *testing.T) {
+		_, _, headers := extractResponseData("HTTP/1.1 200 OK\r\nServer: test\r\n\r\nname: value\r\n")
+		require.Equal(t, map[string]string{"server": "test"}, headers, "body lines were parsed as headers")
+	})
+
+	t.Run("EmptyValue", func(t *testing.T) {
+		// the previous pattern took the next header line as the empty value
+		_, _, headers := extractResponseData("HTTP/1.1 200 OK\r\nX-Empty:\r\nServer: test\r\n\r\n")
+		require.Equal(t, map[string]string{"x-empty": "", "server": "test"}, headers)
+	})
+
+	t.Run("LongBodyLine", func(t *testing.T) {
+		response := "HTTP/1.1 200 OK\r\nServer: test\r\n\r\n" + strings.Repeat("a", 2*maxResponseLineSize)
+		httpVersion, statusCode, headers := extractResponseData(response)
+		require.Equal(t, "1.1", httpVersion)
+		require.Equal(t, 200, statusCode)
+		require.Equal(t, map[string]string{"server": "test"}, headers)
+	})
+}
+
+// regexpResponseData is the previous regexp based extractResponseData
+// matching the whole response, kept to compare the results and the
+// allocations of the scanning one against.
+func regexpResponseData(rawResponse string) (string, int, map[string]string) {
+	headers := make(map[string]string)
+	headerPattern := regexp.MustCompile(`(?m)^([\w-]+):\s*([^\n\r]*)[\n\r]+`)
+	for _, match := range headerPattern.FindAllStringSubmatch(rawResponse, -1) {
+		headers[strings.ToLower(match[1])] = match[2]
+	}
+	statusPattern := regexp.MustCompile(`^HTTP/(\d+\.\d+)\s+(\d+)\s+.*`)
+	statusMatch := statusPattern.FindStringSubmatch(rawResponse)
+	httpVersion := ""
+	statusCode := 0
+	if len(statusMatch) > 2 {
+		httpVersion = statusMatch[1]
+		statusCode, _ = strconv.Atoi(statusMatch[2])
+	}
+	return httpVersion, statusCode, headers
+}
+
+func BenchmarkExtractResponseData(b *testing.B) {
+	var headers strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&headers, "X-Header-%d: value-%d\r\n", i, i)
+	}
+	// a multi-megabyte response, the body lines look like headers
+	response := "HTTP/1.1 200 OK\r\n" + headers.String() + "\r\n" + strings.Repeat("key: value\n", 400000)
+
+	for _, extract := range []struct {
+		name string
+		fn   func(string) (string, int, map[string]string)
+	}{
+		{"Regexp", regexpResponseData},
+		{"Scanner", extractResponseData},
+	} {
+		b.Run(extract.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _, _ = extract.fn(response)
+			}
+		})
+	}
+}
+
+func TestStandardWriterStoredFileName(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileNameFunc func(host, templateID, eventType string) string
+		expected     string
+	}{
+		{"Default", nil, "example_com_git_config.txt"},
+		{"Custom", func(host, templateID, eventType string) string {
+			return fmt.Sprintf("%s-%s-%s.log", templateID, eventType, "20260101")
+		}, "git-config-http-20260101.log"},
+		{"PathTraversal", func(host, templateID, eventType string) string {
+			return "../../" + templateID + ".txt"
+		}, "git-config.txt"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := newTestWriter("")
+			w.storeResponse = true
+			w.storeResponseDir = t.TempDir()
+			w.StoredFileNameFunc = test.fileNameFunc
+
+			w.WriteStoreDebugData("https://example.com", "git-config", "http", "data")
+
+			data, err := os.ReadFile(filepath.Join(w.storeResponseDir, "http", test.expected))
+			require.NoError(t, err)
+			require.Equal(t, "data\n", string(data))
+		})
+	}
+}
+
+func TestStandardWriterMaxStoredResponses(t *testing.T) {
+	logs := captureLogs(t)
+	gologger.DefaultLogger.SetMaxLevel(levels.LevelDebug)
+
+	w := newTestWriter("")
+	w.storeResponse = true
+	w.storeResponseDir = t.TempDir()
+	w.maxStoredResponses = 3
+
+	for i := 0; i < 10; i++ {
+		w.WriteStoreDebugData("https://example.com", "git-config", "http", fmt.Sprintf("response %d", i))
+	}
+	w.WriteStoreDebugData("https://example.com", "tech-detect", "http", "response")
+
+	data, err := os.ReadFile(filepath.Join(w.storeResponseDir, "http", "example_com_git_config.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "response 0\nresponse 1\nresponse 2\n", string(data))
+	_, err = os.Stat(filepath.Join(w.storeResponseDir, "http", "example_com_tech_detect.txt"))
+	require.NoError(t, err, "the maximum is per stored response file")
+	require.Equal(t, 1, strings.Count(logs.String(), "Maximum of 3 stored responses reached"))
+}
+
+func TestStandardWriterJSONAndTextOutput(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	for _, jsonl := range []bool{false, true} {
+		t.Run(fmt.Sprintf("JSONL=%v", jsonl), func(t *testing.T) {
+			dir := t.TempDir()
+			jsonPath, textPath := filepath.Join(dir, "results.jsonl"), filepath.Join(dir, "results.txt")
+			w, err := NewStandardWriter(&types.Options{JSONL: jsonl, JSONOutput: jsonPath, TextOutput: textPath})
+			require.NoError(t, err)
+			w.aurora = aurora.NewAurora(true)
+			w.severityColors = colorizer.New(w.aurora)
+			require.NoError(t, w.Write(&ResultEvent{
+				TemplateID: "git-config",
+				Type:       "http",
+				Matched:    "https://example.com/.git/config",
+				Info:       model.Info{SeverityHolder: severity.Holder{Severity: severity.Medium}},
+			}))
+			w.Close()
+
+			data, err := os.ReadFile(jsonPath)
+			require.NoError(t, err)
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &fields), "json output isn't json")
+			require.Equal(t, "git-config", fields["template-id"])
+
+			data, err = os.ReadFile(textPath)
+			require.NoError(t, err)
+			require.Equal(t, "[git-config] [http] [medium] https://example.com/.git/config\n", string(data))
+		})
+	}
+}
+
+func TestStandardWriterStoreResponseDateFolders(t *testing.T) {
+	now := time.Date(2023, 3, 14, 23, 59, 0, 0, time.UTC)
+	w := newTestWriter("")
+	w.storeResponse = true
+	w.storeResponseDir = t.TempDir()
+	w.storeDateFolders = true
+	w.now = func() time.Time { return now }
+
+	w.WriteStoreDebugData("https://example.com", "git-config", "http", "first")
+	now = now.Add(2 * time.Minute)
+	w.WriteStoreDebugData("https://example.com", "git-config", "http", "second")
+	w.WriteStoreDebugData("example.com:53", "dns-txt", "dns", "third")
+
+	data, err := os.ReadFile(filepath.Join(w.storeResponseDir, "2023-03-14", "http", "example_com_git_config.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "first\n", string(data))
+	data, err = os.ReadFile(filepath.Join(w.storeResponseDir, "2023-03-15", "http", "example_com_git_config.txt"))
+	require.NoError(t, err, "response of the next day wasn't stored in its folder")
+	require.Equal(t, "second\n", string(data))
+	_, err = os.Stat(filepath.Join(w.storeResponseDir, "2023-03-15", "dns"))
+	require.NoError(t, err)
+
+	// the finding timestamp is taken from the same clock
+	event := &ResultEvent{TemplateID: "git-config"}
+	require.NoError(t, w.Write(event))
+	require.Equal(t, now, event.Timestamp)
+
+	t.Run("Disabled", func(t *testing.T) {
+		w := newTestWriter("")
+		w.storeResponse = true
+		w.storeResponseDir = t.TempDir()
+		w.now = func() time.Time { return now }
+		w.WriteStoreDebugData("https://example.com", "git-config", "http", "first")
+		_, err := os.Stat(filepath.Join(w.storeResponseDir, "http", "example_com_git_config.txt"))
+		require.NoError(t, err)
+	})
+}
+
+func TestStandardWriterStoredFolderCreation(t *testing.T) {
+	var creations atomic.Int32
+	w := newTestWriter("")
+	w.storeResponse = true
+	w.storeResponseDir = t.TempDir()
+	w.createFolder = func(path string) error {
+		creations.Add(1)
+		return fileutil.CreateFolder(path)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w.WriteStoreDebugData(fmt.Sprintf("https://host-%d.example.com", i), "git-config", "http", "response")
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), creations.Load(), "the folder was created more than once")
+	entries, err := os.ReadDir(filepath.Join(w.storeResponseDir, "http"))
+	require.NoError(t, err)
+	require.Len(t, entries, 50, "responses were lost")
+
+	t.Run("RetriedOnError", func(t *testing.T) {
+		var fail atomic.Bool
+		fail.Store(true)
+		w := newTestWriter("")
+		w.storeResponse = true
+		w.storeResponseDir = t.TempDir()
+		w.createFolder = func(path string) error {
+			if fail.Load() {
+				return errors.New("disk full")
+			}
+			return fileutil.CreateFolder(path)
+		}
+		logs := captureLogs(t)
+		w.WriteStoreDebugData("https://example.com", "git-config", "http", "first")
+		require.Contains(t, logs.String(), "could not create stored responses folder "+filepath.Join(w.storeResponseDir, "http")+": disk full")
+		fail.Store(false)
+		w.WriteStoreDebugData("https://example.com", "git-config", "http", "second")
+
+		data, err := os.ReadFile(filepath.Join(w.storeResponseDir, "http", "example_com_git_config.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "second\n", string(data))
+	})
+
+	t.Run("WriteError", func(t *testing.T) {
+		w := newTestWriter("")
+		w.storeResponse = true
+		w.storeResponseDir = t.TempDir()
+		// a folder in place of the file fails the write
+		require.NoError(t, os.MkdirAll(filepath.Join(w.storeResponseDir, "http", "example_com_git_config.txt"), 0755))
+
+		logs := captureLogs(t)
+		w.WriteStoreDebugData("https://example.com", "git-config", "http", "response")
+		require.Contains(t, logs.String(), "Could not store response of https://example.com")
+	})
+}
+
+func TestStandardWriterStoredFilePool(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	w, err := NewStandardWriter(&types.Options{JSONL: true, StoreResponse: true, StoreResponseDir: t.TempDir()})
+	require.NoError(t, err)
+
+	response := strings.Repeat("a", 64*1024)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.WriteStoreDebugData("https://example.com", "git-config", "http", response)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 1, w.storedPool.opened, "the stored response file was opened again")
+	require.Len(t, w.storedPool.files, 1)
+	w.Close()
+	require.Empty(t, w.storedPool.files, "stored response files were not closed")
+
+	data, err := os.ReadFile(filepath.Join(w.storeResponseDir, "http", "example_com_git_config.txt"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 20)
+	for _, line := range lines {
+		require.Equal(t, response, line, "stored responses were interleaved")
+	}
+
+	t.Run("Eviction", func(t *testing.T) {
+		dir := t.TempDir()
+		pool := newStoredFilePool(1)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, pool.write(filepath.Join(dir, "first.txt"), []byte("first\n")))
+			require.NoError(t, pool.write(filepath.Join(dir, "second.txt"), []byte("second\n")))
+		}
+		require.Len(t, pool.files, 1)
+		require.Equal(t, 6, pool.opened)
+		require.NoError(t, pool.close())
+
+		data, err := os.ReadFile(filepath.Join(dir, "first.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "first\nfirst\nfirst\n", string(data))
+	})
+}
+
+func TestStandardWriterResponseStatusLine(t *testing.T) {
+	response := "HTTP/1.1 404 Not Found\r\nContent-Type: text/html\r\nX-Request-Id: 42\r\n\r\n<html>missing</html>"
+	reconstruct := func(statusLine bool, response string) string {
+		outputFile := &testWriteCloser{}
+		w := newTestWriter("")
+		w.jsonReqResp = true
+		w.responseBody = true
+		w.statusLine = statusLine
+		w.outputFile = outputFile
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Response: response}))
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+		decoded, err := base64.StdEncoding.DecodeString(fields["response"].(string))
+		require.NoError(t, err)
+		return string(decoded)
+	}
+
+	reconstructed := reconstruct(true, response)
+	require.True(t, strings.HasPrefix(reconstructed, "HTTP/1.1 404 Not Found\r\n"), reconstructed)
+	parsed, err := http.ReadResponse(bufio.NewReader(strings.NewReader(reconstructed)), nil)
+	require.NoError(t, err, "reconstructed response is not valid http")
+	require.Equal(t, http.StatusNotFound, parsed.StatusCode)
+	require.Equal(t, "HTTP/1.1", parsed.Proto)
+	require.Equal(t, "text/html", parsed.Header.Get("Content-Type"))
+	require.Equal(t, "42", parsed.Header.Get("X-Request-Id"))
+	body, err := io.ReadAll(parsed.Body)
+	require.NoError(t, err)
+	require.Equal(t, "<html>missing</html>", string(body))
+	require.NotContains(t, reconstructed, "Status code:")
+
+	t.Run("NoStatusLine", func(t *testing.T) {
+		reconstructed := reconstruct(true, "Content-Type: text/html\r\n\r\n")
+		require.True(t, strings.HasPrefix(reconstructed, "HTTP/1.1 000\r\n"), reconstructed)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		reconstructed := reconstruct(false, response)
+		require.True(t, strings.HasPrefix(reconstructed, "HTTP version: 1.1\nStatus code: 404\n"), "the default preamble changed")
+	})
+}
+
+func TestStandardWriterDisableResponseReconstruction(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	response := "HTTP/1.1 200 OK\r\nX-First: 1\r\nContent-Type: text/html\r\n\r\n<html>\x00\xff</html>"
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.jsonReqResp = true
+	w.outputFile = outputFile
+	w.noReconstruction = true
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Response: response}))
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+	decoded, err := base64.StdEncoding.DecodeString(fields["response"].(string))
+	require.NoError(t, err)
+	require.Equal(t, response, string(decoded), "original response was modified")
+}
+
+func TestStandardWriterSeparateResponseReconstruction(t *testing.T) {
+	var context map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Context map[string]interface{} `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		context = body.Context
+	}))
+	defer ts.Close()
+
+	response := "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<html></html>"
+	decode := func(t *testing.T, fields map[string]interface{}) string {
+		decoded, err := base64.StdEncoding.DecodeString(fields["response"].(string))
+		require.NoError(t, err)
+		return string(decoded)
+	}
+	tests := []struct {
+		name            string
+		noFile          bool
+		noWebhook       bool
+		originalFile    bool
+		originalWebhook bool
+	}{
+		{name: "File", noFile: true, originalFile: true},
+		{name: "Webhook", noWebhook: true, originalWebhook: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+			w, err := NewStandardWriter(&types.Options{JSONL: true, JSONRequests: true, DisableFileResponseReconstruction: test.noFile, DisableWebhookResponseReconstruction: test.noWebhook})
+			require.NoError(t, err)
+			outputFile := &testWriteCloser{}
+			w.outputFile = outputFile
+			w.AstraWebhook = ts.URL
+			request := "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Request: request, Response: response}))
+
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &fields))
+			require.Equal(t, test.originalFile, decode(t, fields) == response, "unexpected written response %q", decode(t, fields))
+			require.Equal(t, test.originalWebhook, decode(t, context) == response, "unexpected webhook response %q", decode(t, context))
+			require.Contains(t, decode(t, fields)+decode(t, context), "Status code: 200", "one of the responses wasn't reconstructed")
+			require.Equal(t, fields["request"], context["request"], "the request differs between the file and the webhook")
+			require.Equal(t, fields["seq"], context["seq"])
+		})
+	}
+}
+
+func BenchmarkStandardWriterWriteResponse(b *testing.B) {
+	var headers strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&headers, "X-Header-%d: value-%d\r\n", i, i)
+	}
+	response := "HTTP/1.1 200 OK\r\n" + headers.String() + "\r\n" + strings.Repeat("<p>body</p>\n", 1000)
+
+	for _, noReconstruction := range []bool{false, true} {
+		name := "Reconstructed"
+		if noReconstruction {
+			name = "Original"
+		}
+		b.Run(name, func(b *testing.B) {
+			w := newTestWriter("http://webhook.example.com")
+			w.httpClient = &http.Client{Transport: noopTransport{}}
+			w.noReconstruction, w.noWebhookRecon = noReconstruction, noReconstruction
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = w.Write(&ResultEvent{TemplateID: "test", Response: response})
+			}
+		})
+	}
+}
+
+// noopTransport is a http transport answering all the requests with an empty response
+type noopTransport struct{}
+
+func (noopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header), Request: req}, nil
+}
+
+func TestStandardWriterUpdateInteraction(t *testing.T) {
+	type request struct {
+		Meta    AstraMeta              `json:"meta"`
+		Context map[string]interface{} `json:"context"`
+	}
+	var mu sync.Mutex
+	var requests []request
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body request
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, body)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	event := &ResultEvent{TemplateID: "blind-ssrf", Host: "https://example.com", Matched: "https://example.com/fetch"}
+	require.NoError(t, w.Write(event))
+	require.NotEmpty(t, event.EventID)
+	require.Len(t, requests, 1)
+	require.Equal(t, event.EventID, requests[0].Context["event-id"], "original alert has no event id")
+
+	// the interaction arrives on a later interactsh poll
+	interaction := &server.Interaction{Protocol: "dns", UniqueID: "c1", RawRequest: "\x00\x01query"}
+	require.NoError(t, w.UpdateInteraction(event.EventID, interaction))
+	require.Len(t, requests, 2)
+	require.Equal(t, alertUpdatedEvent, requests[1].Meta.Event)
+	require.Equal(t, event.EventID, requests[1].Context["event-id"])
+	update := requests[1].Context["interaction"].(map[string]interface{})
+	require.Equal(t, "dns", update["protocol"])
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte(interaction.RawRequest)), update["raw-request"])
+	require.Equal(t, "\x00\x01query", interaction.RawRequest, "shared interaction was modified")
+
+	require.ErrorContains(t, w.UpdateInteraction("", interaction), "empty event id")
+	require.ErrorContains(t, w.UpdateInteraction(event.EventID, nil), "nil interaction")
+	require.Len(t, requests, 2)
+}
+
+func TestDecolorizerRegex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"CSI", "\x1b[32mmatched\x1b[0m"},
+		{"CSIExtended", "\x1b[1;38;5;208mmatched\x1b[39;49m\x1b[?25l"},
+		{"OSCWithBEL", "\x1b]0;nuclei\x07matched"},
+		{"OSCWithST", "\x1b]8;;https://example.com\x1b\\matched\x1b]8;;\x1b\\"},
+		{"Reset", "\x1bcmatched\x1b[m"},
+		{"Plain", "matched"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, "matched", decolorizerRegex.ReplaceAllString(test.input, ""))
+		})
+	}
+}
+
+// setAstraEnv sets the environment required by NewStandardWriter, pointing
+// both the webhook and the api service to a test server using handler.
+func TestStandardWriterExtraMeta(t *testing.T) {
+	type request struct {
+		method string
+		meta   map[string]interface{}
+	}
+	var mu sync.Mutex
+	var requests []request
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Meta map[string]interface{} `json:"meta"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, request{method: r.Method, meta: body.Meta})
+		mu.Unlock()
+	})
+
+	t.Run("AlertAndStatusEvents", func(t *testing.T) {
+		setAstraEnv(t, handler)
+		w, err := NewStandardWriter(&types.Options{ExtraMeta: map[string]string{"tenant": "acme", "environment": "staging"}})
+		require.NoError(t, err)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		w.Close()
+
+		// status change, scan.started, alert, scan.summary, status change and scan.complete
+		require.Len(t, requests, 6)
+		for _, request := range requests {
+			require.Equal(t, "acme", request.meta["tenant"], request.method)
+			require.Equal(t, "staging", request.meta["environment"], request.method)
+			if request.method == http.MethodPost {
+				require.Equal(t, "scan-id", request.meta["scanId"])
+				require.NotEmpty(t, request.meta["event"])
+			}
+		}
+	})
+
+	t.Run("NoExtraMeta", func(t *testing.T) {
+		data, err := json.Marshal(AstraMeta{Event: "alert", ScanId: "scan-id"})
+		require.NoError(t, err)
+		require.Equal(t, `{"event":"alert","auditId":"","jobId":"","scanId":"scan-id","hostname":""}`, string(data))
+	})
+
+	t.Run("ReservedKey", func(t *testing.T) {
+		setAstraEnv(t, handler)
+		_, err := NewStandardWriter(&types.Options{ExtraMeta: map[string]string{"scanId": "other"}})
+		require.ErrorContains(t, err, `"scanId" is a reserved meta field`)
+
+		data, err := json.Marshal(AstraMeta{Event: "alert", Extra: map[string]string{"event": "other", "webhookToken": "leaked"}})
+		require.NoError(t, err)
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		require.Equal(t, "alert", fields["event"])
+		require.NotContains(t, fields, "webhookToken")
+	})
+}
+
+func TestStandardWriterMetaHostname(t *testing.T) {
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+	t.Run("Override", func(t *testing.T) {
+		w, err := NewStandardWriter(&types.Options{MetaHostname: "scanner-eu-1"})
+		require.NoError(t, err)
+		require.Equal(t, "scanner-eu-1", w.AstraMeta.Hostname)
+	})
+
+	t.Run("MachineHostname", func(t *testing.T) {
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+
+		w, err := NewStandardWriter(&types.Options{})
+		require.NoError(t, err)
+		require.Equal(t, hostname, w.AstraMeta.Hostname)
+	})
+}
+
+func setAstraEnv(t *testing.T, handler http.Handler) *httptest.Server {
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	env := map[string]string{
+		"auditId":           "audit-id",
+		"jobId":             "job-id",
+		"scanId":            "scan-id",
+		"webhookToken":      "webhook-token",
+		"webhookUrl":        ts.URL,
+		"DAST_API_SVC_NAME": strings.TrimPrefix(ts.URL, "http://"),
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	return ts
+}
+
 type testWriteCloser struct {
 	strings.Builder
 }
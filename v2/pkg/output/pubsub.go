@@ -0,0 +1,200 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// pubsubEndpoint is the endpoint of the Google Cloud Pub/Sub REST API
+const pubsubEndpoint = "https://pubsub.googleapis.com"
+
+// pubsubScope is the oauth2 scope of the Pub/Sub API
+const pubsubScope = "https://www.googleapis.com/auth/pubsub"
+
+// pubsubBatchSize is the maximum number of messages published at once
+const pubsubBatchSize = 100
+
+// pubsubFlushDelay is the maximum delay a message waits for its batch to fill
+const pubsubFlushDelay = time.Second
+
+// pubsubMessage is a message published to a Pub/Sub topic, its data is
+// base64 encoded by the json encoding.
+type pubsubMessage struct {
+	Data       []byte            `json:"data"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// done is called with the outcome of the publish of the message
+	done func(error)
+}
+
+// pubsubTransport publishes the alerts to a Google Cloud Pub/Sub topic
+// with its REST API, in batches. The emulator at PUBSUB_EMULATOR_HOST is
+// used if set, else the requests are authorized with the application
+// default credentials, eg. the GOOGLE_APPLICATION_CREDENTIALS key file or
+// the service account of the instance, whose tokens are refreshed once
+// expired.
+type pubsubTransport struct {
+	client  *http.Client
+	url     string
+	mu      sync.Mutex
+	pending []pubsubMessage
+	timer   *time.Timer
+	// inflight is the number of batches being published
+	inflight sync.WaitGroup
+}
+
+var _ AlertTransport = &pubsubTransport{}
+
+// newPubSubTransport creates a new transport publishing to the topic, either
+// a projects/<project>/topics/<topic> path or the name of a topic of the
+// GOOGLE_CLOUD_PROJECT project.
+func newPubSubTransport(topic string, client *http.Client) (*pubsubTransport, error) {
+	topicPath, err := pubsubTopicPath(topic, os.Getenv("GOOGLE_CLOUD_PROJECT"))
+	if err != nil {
+		return nil, err
+	}
+	transport := &pubsubTransport{client: client, url: pubsubEndpoint + "/v1/" + topicPath + ":publish"}
+	if host := os.Getenv("PUBSUB_EMULATOR_HOST"); host != "" {
+		transport.url = "http://" + host + "/v1/" + topicPath + ":publish"
+		return transport, nil
+	}
+	source, err := google.DefaultTokenSource(context.Background(), pubsubScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "no pubsub credentials")
+	}
+	transport.client = &http.Client{
+		Timeout:   client.Timeout,
+		Transport: &oauth2.Transport{Source: source, Base: client.Transport},
+	}
+	return transport, nil
+}
+
+// pubsubTopicPath returns the full path of the topic
+func pubsubTopicPath(topic, project string) (string, error) {
+	if strings.HasPrefix(topic, "projects/") {
+		parts := strings.Split(topic, "/")
+		if len(parts) != 4 || parts[1] == "" || parts[2] != "topics" || parts[3] == "" {
+			return "", fmt.Errorf("invalid pubsub topic %q: expected projects/<project>/topics/<topic>", topic)
+		}
+		return topic, nil
+	}
+	if topic == "" || strings.Contains(topic, "/") {
+		return "", fmt.Errorf("invalid pubsub topic %q: expected a topic name or projects/<project>/topics/<topic>", topic)
+	}
+	if project == "" {
+		return "", fmt.Errorf("invalid pubsub topic %q: GOOGLE_CLOUD_PROJECT must be set for a topic name", topic)
+	}
+	return "projects/" + project + "/topics/" + topic, nil
+}
+
+// Publish adds the alert to the pending batch, publishing it once full.
+// The batch is published after pubsubFlushDelay otherwise.
+func (t *pubsubTransport) Publish(ctx context.Context, payload []byte, attributes map[string]string, done func(error)) {
+	t.mu.Lock()
+	t.pending = append(t.pending, pubsubMessage{Data: payload, Attributes: attributes, done: done})
+	if len(t.pending) < pubsubBatchSize {
+		if t.timer == nil {
+			t.timer = time.AfterFunc(pubsubFlushDelay, t.flushPending)
+		}
+		t.mu.Unlock()
+		return
+	}
+	batch := t.take()
+	t.mu.Unlock()
+
+	_ = t.publish(ctx, batch)
+}
+
+// Close publishes the pending batch and waits for the batches being
+// published, returning the error of the pending batch if any.
+func (t *pubsubTransport) Close() error {
+	t.mu.Lock()
+	batch := t.take()
+	t.mu.Unlock()
+
+	var err error
+	if len(batch) > 0 {
+		err = t.publish(context.Background(), batch)
+	}
+	t.inflight.Wait()
+	return err
+}
+
+// flushPending publishes the pending batch once its delay expired, the
+// failure being reported to the messages.
+func (t *pubsubTransport) flushPending() {
+	t.mu.Lock()
+	batch := t.take()
+	t.mu.Unlock()
+
+	if len(batch) > 0 {
+		_ = t.publish(context.Background(), batch)
+	}
+}
+
+// take removes the pending batch returning it, tracked as inflight until
+// published. The mutex must be held.
+func (t *pubsubTransport) take() []pubsubMessage {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	batch := t.pending
+	t.pending = nil
+	if len(batch) > 0 {
+		t.inflight.Add(1)
+	}
+	return batch
+}
+
+// publish publishes the messages to the topic, calling done of each
+// message with the outcome.
+func (t *pubsubTransport) publish(ctx context.Context, messages []pubsubMessage) error {
+	defer t.inflight.Done()
+
+	err := t.post(ctx, messages)
+	for _, message := range messages {
+		if message.done != nil {
+			message.done(err)
+		}
+	}
+	return err
+}
+
+// post posts the messages to the publish endpoint of the topic
+func (t *pubsubTransport) post(ctx context.Context, messages []pubsubMessage) error {
+	body, err := jsonEncoder.Marshal(struct {
+		Messages []pubsubMessage `json:"messages"`
+	}{Messages: messages})
+	if err != nil {
+		return errors.Wrap(err, "could not marshal pubsub messages")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create pubsub request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not publish to pubsub")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("could not publish to pubsub: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
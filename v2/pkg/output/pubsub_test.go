@@ -0,0 +1,224 @@
+package output
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+// pubsubEmulator is a stub of the Pub/Sub emulator recording the published batches
+type pubsubEmulator struct {
+	mu      sync.Mutex
+	paths   []string
+	batches [][]pubsubMessage
+}
+
+func newPubSubEmulator(t *testing.T) *pubsubEmulator {
+	emulator := &pubsubEmulator{}
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []pubsubMessage `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		emulator.mu.Lock()
+		emulator.paths = append(emulator.paths, r.URL.Path)
+		emulator.batches = append(emulator.batches, body.Messages)
+		emulator.mu.Unlock()
+		_, _ = rw.Write([]byte(`{"messageIds":["1"]}`))
+	}))
+	t.Cleanup(ts.Close)
+	t.Setenv("PUBSUB_EMULATOR_HOST", strings.TrimPrefix(ts.URL, "http://"))
+	return emulator
+}
+
+func TestStandardWriterPubSub(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Meta AstraMeta `json:"meta"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		events = append(events, body.Meta.Event)
+		mu.Unlock()
+	}))
+	emulator := newPubSubEmulator(t)
+
+	w, err := NewStandardWriter(&types.Options{JSONL: true, AlertTransport: AlertTransportPubSub, PubSubTopic: "projects/astra/topics/alerts"})
+	require.NoError(t, err)
+	for _, templateID := range []string{"git-config", "tech-detect", "log4j-rce"} {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: templateID}))
+	}
+	require.Equal(t, uint64(0), w.Metrics().AlertsSent, "the pending alerts were counted as sent")
+	w.Close()
+
+	require.Equal(t, []string{"/v1/projects/astra/topics/alerts:publish"}, emulator.paths, "the outstanding alerts weren't published on close")
+	require.Len(t, emulator.batches[0], 3)
+	for i, templateID := range []string{"git-config", "tech-detect", "log4j-rce"} {
+		message := emulator.batches[0][i]
+		require.Equal(t, "alert", message.Attributes["event"])
+		require.NotEmpty(t, message.Attributes["idempotencyKey"])
+
+		var request AstraAlertRequest
+		require.NoError(t, json.Unmarshal(message.Data, &request))
+		require.Equal(t, "alert", request.Meta.Event)
+		require.Contains(t, string(request.Context), `"template-id":"`+templateID+`"`)
+	}
+	require.Equal(t, uint64(3), w.Metrics().AlertsSent)
+	require.NotContains(t, events, "alert", "alerts were posted to the webhook")
+	require.Contains(t, events, "scan.complete")
+
+	t.Run("FullBatch", func(t *testing.T) {
+		emulator := newPubSubEmulator(t)
+		transport, err := newPubSubTransport("projects/astra/topics/alerts", http.DefaultClient)
+		require.NoError(t, err)
+		for i := 0; i < pubsubBatchSize+1; i++ {
+			transport.Publish(context.Background(), []byte("{}"), nil, nil)
+		}
+		require.Len(t, emulator.batches, 1, "the full batch wasn't published")
+		require.Len(t, emulator.batches[0], pubsubBatchSize)
+
+		require.NoError(t, transport.Close())
+		require.Len(t, emulator.batches, 2)
+		require.Len(t, emulator.batches[1], 1)
+		require.NoError(t, transport.Close(), "closing without pending alerts failed")
+		require.Len(t, emulator.batches, 2)
+	})
+
+	t.Run("PublishError", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			http.Error(rw, "topic not found", http.StatusNotFound)
+		}))
+		defer ts.Close()
+		t.Setenv("PUBSUB_EMULATOR_HOST", strings.TrimPrefix(ts.URL, "http://"))
+
+		transport, err := newPubSubTransport("projects/astra/topics/missing", http.DefaultClient)
+		require.NoError(t, err)
+		var published error
+		transport.Publish(context.Background(), []byte("{}"), nil, func(err error) { published = err })
+		require.ErrorContains(t, transport.Close(), "topic not found")
+		require.ErrorContains(t, published, "topic not found", "the failure wasn't reported to the alert")
+	})
+
+	t.Run("FailedBatch", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			http.Error(rw, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+		t.Setenv("PUBSUB_EMULATOR_HOST", strings.TrimPrefix(ts.URL, "http://"))
+
+		spoolFile := filepath.Join(t.TempDir(), "spool.jsonl")
+		w, err := NewStandardWriter(&types.Options{JSONL: true, AlertTransport: AlertTransportPubSub, PubSubTopic: "projects/astra/topics/alerts", WebhookSpoolFile: spoolFile})
+		require.NoError(t, err)
+		for _, templateID := range []string{"git-config", "tech-detect"} {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: templateID}))
+		}
+		w.Close()
+
+		require.Equal(t, uint64(0), w.Metrics().AlertsSent)
+		require.Equal(t, uint64(2), w.Metrics().PublishFailures)
+		spooled, err := os.ReadFile(spoolFile)
+		require.NoError(t, err)
+		require.Equal(t, 2, strings.Count(string(spooled), "\n"), "the alerts of the failed batch weren't spooled")
+
+		// the spooled alerts are published again once the topic is back
+		emulator := newPubSubEmulator(t)
+		w, err = NewStandardWriter(&types.Options{JSONL: true, AlertTransport: AlertTransportPubSub, PubSubTopic: "projects/astra/topics/alerts", WebhookSpoolFile: spoolFile})
+		require.NoError(t, err)
+		w.Close()
+		require.Len(t, emulator.batches, 1)
+		require.Len(t, emulator.batches[0], 2)
+		require.Equal(t, uint64(2), w.Metrics().AlertsSent)
+		require.NoFileExists(t, spoolFile)
+	})
+
+	t.Run("Credentials", func(t *testing.T) {
+		var tokens atomic.Int32
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			// the tokens expire right away to be refreshed on every publish
+			_, _ = fmt.Fprintf(rw, `{"access_token":"token-%d","token_type":"Bearer","expires_in":1}`, tokens.Add(1))
+		}))
+		defer tokenServer.Close()
+		var authorizations []string
+		pubsub := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+		}))
+		defer pubsub.Close()
+
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		credentials, err := json.Marshal(map[string]string{
+			"type":         "service_account",
+			"client_email": "nuclei@astra.iam.gserviceaccount.com",
+			"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+			"token_uri":    tokenServer.URL,
+		})
+		require.NoError(t, err)
+		credentialsFile := filepath.Join(t.TempDir(), "credentials.json")
+		require.NoError(t, os.WriteFile(credentialsFile, credentials, 0600))
+		t.Setenv("PUBSUB_EMULATOR_HOST", "")
+		t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentialsFile)
+
+		transport, err := newPubSubTransport("projects/astra/topics/alerts", http.DefaultClient)
+		require.NoError(t, err)
+		require.Equal(t, "https://pubsub.googleapis.com/v1/projects/astra/topics/alerts:publish", transport.url)
+		transport.url = pubsub.URL
+		for i := 0; i < 2; i++ {
+			transport.Publish(context.Background(), []byte("{}"), nil, nil)
+			require.NoError(t, transport.Close())
+		}
+		require.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authorizations, "the expired token wasn't refreshed")
+
+		t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+		_, err = newPubSubTransport("projects/astra/topics/alerts", http.DefaultClient)
+		require.ErrorContains(t, err, "no pubsub credentials")
+	})
+
+	t.Run("InvalidTransport", func(t *testing.T) {
+		_, err := NewStandardWriter(&types.Options{AlertTransport: "sqs"})
+		require.ErrorContains(t, err, `invalid alert transport "sqs"`)
+	})
+}
+
+func TestPubSubTopicPath(t *testing.T) {
+	tests := []struct {
+		topic    string
+		project  string
+		expected string
+		err      string
+	}{
+		{topic: "projects/astra/topics/alerts", expected: "projects/astra/topics/alerts"},
+		{topic: "alerts", project: "astra", expected: "projects/astra/topics/alerts"},
+		{topic: "alerts", err: "GOOGLE_CLOUD_PROJECT must be set"},
+		{topic: "projects/astra/subscriptions/alerts", err: "expected projects/<project>/topics/<topic>"},
+		{topic: "projects//topics/alerts", err: "expected projects/<project>/topics/<topic>"},
+		{topic: "", project: "astra", err: "expected a topic name"},
+		{topic: "astra/alerts", project: "astra", err: "expected a topic name"},
+	}
+	for _, test := range tests {
+		path, err := pubsubTopicPath(test.topic, test.project)
+		if test.err != "" {
+			require.ErrorContains(t, err, test.err, test.topic)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, test.expected, path)
+	}
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// resolveTimeout is the maximum time spent resolving the host of a result
+const resolveTimeout = 2 * time.Second
+
+// hostResolver resolves hostnames to ip addresses
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// resolveIP returns the ip address of the host of the result. Lookups are
+// cached for the lifetime of the writer, including failed ones so an
+// unresolvable host doesn't slow down every result.
+func (w *StandardWriter) resolveIP(host string) string {
+	hostname := resultHostname(host)
+	if hostname == "" {
+		return ""
+	}
+	if net.ParseIP(hostname) != nil {
+		return hostname
+	}
+	if ip, ok := w.resolvedIPs.Load(hostname); ok {
+		return ip.(string)
+	}
+
+	resolver := w.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	var ip string
+	if addresses, err := resolver.LookupHost(ctx, hostname); err == nil && len(addresses) > 0 {
+		ip = addresses[0]
+	}
+	w.resolvedIPs.Store(hostname, ip)
+	return ip
+}
+
+// resultHostname returns the hostname of the host of a result which
+// can be an url, a host:port pair or a bare hostname.
+func resultHostname(host string) string {
+	if strings.Contains(host, "://") {
+		parsed, err := url.Parse(host)
+		if err != nil {
+			return ""
+		}
+		return parsed.Hostname()
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return hostname
+	}
+	return strings.Trim(host, "[]")
+}
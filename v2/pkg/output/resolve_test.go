@@ -0,0 +1,73 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardWriterResolveIP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	resolver := &testResolver{addresses: map[string][]string{"example.com": {"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"}}}
+	w := newTestWriter(ts.URL)
+	w.enrichIP = true
+	w.resolver = resolver
+
+	tests := []struct {
+		name     string
+		host     string
+		ip       string
+		expected string
+	}{
+		{"URL", "https://example.com/login", "", "93.184.216.34"},
+		{"HostPort", "example.com:443", "", "93.184.216.34"},
+		{"Hostname", "example.com", "", "93.184.216.34"},
+		{"AlreadyIP", "http://10.0.0.1:8080", "", "10.0.0.1"},
+		{"AlreadyIPv6", "[::1]:22", "", "::1"},
+		{"IPSet", "example.com", "192.0.2.1", "192.0.2.1"},
+		{"Unresolvable", "missing.example.com", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event := &ResultEvent{TemplateID: "test", Host: test.host, IP: test.ip}
+			require.NoError(t, w.Write(event))
+			require.Equal(t, test.expected, event.IP)
+		})
+	}
+
+	t.Run("Cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Host: "https://example.com"}))
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Host: "missing.example.com"}))
+		}
+		require.Equal(t, map[string]int{"example.com": 1, "missing.example.com": 1}, resolver.lookups)
+	})
+}
+
+// testResolver is a resolver returning fixed addresses and counting the lookups
+type testResolver struct {
+	mu        sync.Mutex
+	addresses map[string][]string
+	lookups   map[string]int
+}
+
+func (r *testResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lookups == nil {
+		r.lookups = make(map[string]int)
+	}
+	r.lookups[host]++
+	if addresses, ok := r.addresses[host]; ok {
+		return addresses, nil
+	}
+	return nil, errors.New("no such host")
+}
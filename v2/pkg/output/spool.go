@@ -0,0 +1,155 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+)
+
+// spooledAlert is an undelivered alert persisted in the spool file
+type spooledAlert struct {
+	TemplateURL    string          `json:"template-url"`
+	Event          string          `json:"event,omitempty"`
+	Context        json.RawMessage `json:"context,omitempty"`
+	IdempotencyKey string          `json:"idempotency-key,omitempty"`
+	Body           []byte          `json:"body,omitempty"`
+	WebhookURL     string          `json:"webhook-url,omitempty"`
+}
+
+// alertSpool is a file of undelivered alerts, one json object per line,
+// redelivered on the next replay.
+type alertSpool struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// newAlertSpool returns a spool of undelivered alerts stored at path
+func newAlertSpool(path string) *alertSpool {
+	return &alertSpool{path: path}
+}
+
+// add appends the alert to the spool file
+func (s *alertSpool) add(alert *alert) error {
+	data, err := jsonEncoder.Marshal(spooledAlert{
+		TemplateURL:    alert.templateURL,
+		Event:          alert.event,
+		Context:        alert.context,
+		IdempotencyKey: alert.idempotencyKey,
+		Body:           alert.body,
+		WebhookURL:     alert.webhookURL,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not marshal spooled alert")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrap(err, "could not open spool file")
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return errors.Wrap(err, "could not write spool file")
+	}
+	return file.Close()
+}
+
+// replay calls deliver for every spooled alert and rewrites the spool
+// with the alerts that couldn't be delivered, removing it once empty.
+// The replay stops at the first failed delivery, the webhook being still
+// down, keeping the rest of the spool for the next replay.
+func (s *alertSpool) replay(deliver func(*alert) bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not open spool file")
+	}
+
+	var remaining [][]byte
+	failed := false
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var spooled spooledAlert
+			if failed {
+				remaining = append(remaining, line)
+			} else if err := json.Unmarshal(line, &spooled); err != nil {
+				gologger.Warning().Msgf("Dropping malformed spooled alert: %s\n", err)
+			} else if !deliver(&alert{
+				templateURL:    spooled.TemplateURL,
+				event:          spooled.Event,
+				context:        spooled.Context,
+				idempotencyKey: spooled.IdempotencyKey,
+				body:           spooled.Body,
+				webhookURL:     spooled.WebhookURL,
+			}) {
+				remaining = append(remaining, line)
+				failed = true
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			file.Close()
+			return errors.Wrap(readErr, "could not read spool file")
+		}
+	}
+	file.Close()
+
+	if len(remaining) == 0 {
+		return os.Remove(s.path)
+	}
+	tempPath := s.path + ".tmp"
+	if err := os.WriteFile(tempPath, append(bytes.Join(remaining, []byte("\n")), '\n'), 0644); err != nil {
+		return errors.Wrap(err, "could not write spool file")
+	}
+	return os.Rename(tempPath, s.path)
+}
+
+// ReplaySpool attempts to redeliver the alerts spooled after failed
+// deliveries, keeping the ones that still can't be delivered.
+func (w *StandardWriter) ReplaySpool() error {
+	if w.spool == nil {
+		return nil
+	}
+	if w.alertTransport != nil {
+		// the alerts are published once the spool is released, the ones
+		// still failing being spooled again
+		var alerts []*alert
+		err := w.spool.replay(func(alert *alert) bool {
+			alerts = append(alerts, alert)
+			return true
+		})
+		for _, alert := range alerts {
+			w.publishAlert(alert)
+		}
+		return err
+	}
+	return w.spool.replay(func(alert *alert) bool {
+		resp, err := w.sendAlert(alert)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		if resp.StatusCode < http.StatusBadRequest {
+			w.metrics.alertsSent.Add(1)
+		}
+		return !shouldRetryStatus(resp.StatusCode)
+	})
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardWriterSpool(t *testing.T) {
+	var available atomic.Bool
+	var requests atomic.Int32
+	var mu sync.Mutex
+	var delivered []string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !available.Load() {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		mu.Lock()
+		delivered = append(delivered, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	spoolFile := filepath.Join(t.TempDir(), "spool.jsonl")
+	w := newTestWriter(ts.URL)
+	w.spool = newAlertSpool(spoolFile)
+
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "first", Host: "https://example.com"}))
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "second", Host: "https://example.com"}))
+
+	data, err := os.ReadFile(spoolFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2, "failed alerts were not spooled")
+	require.Contains(t, lines[0], `"template-url"`)
+	require.Contains(t, lines[0], `"idempotency-key"`)
+
+	t.Run("ReplayFailure", func(t *testing.T) {
+		requests.Store(0)
+		require.NoError(t, w.ReplaySpool())
+		require.Equal(t, int32(w.webhookRetries+1), requests.Load(), "the replay went on after a failed delivery")
+		require.FileExists(t, spoolFile)
+		replayed, err := os.ReadFile(spoolFile)
+		require.NoError(t, err)
+		require.Equal(t, data, replayed, "undelivered alerts were not kept")
+	})
+
+	t.Run("ReplaySuccess", func(t *testing.T) {
+		available.Store(true)
+		require.NoError(t, w.ReplaySpool())
+		require.NoFileExists(t, spoolFile, "spool was not drained")
+		require.Len(t, delivered, 2)
+		require.NotEmpty(t, delivered[0])
+		require.Equal(t, uint64(2), w.Metrics().AlertsSent)
+
+		// replaying an empty spool is a no-op
+		require.NoError(t, w.ReplaySpool())
+		require.Len(t, delivered, 2)
+	})
+
+	t.Run("PartialReplay", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(spoolFile, []byte("not json\n"+lines[0]+"\n"+lines[1]+"\n"), 0644))
+
+		calls := 0
+		require.NoError(t, w.spool.replay(func(alert *alert) bool {
+			calls++
+			return calls == 1
+		}))
+		require.Equal(t, 2, calls, "malformed entries should be skipped")
+		replayed, err := os.ReadFile(spoolFile)
+		require.NoError(t, err)
+		require.Equal(t, lines[1]+"\n", string(replayed))
+	})
+
+	t.Run("StopsAtFailure", func(t *testing.T) {
+		spooled := []byte(lines[0] + "\n" + lines[1] + "\n")
+		require.NoError(t, os.WriteFile(spoolFile, spooled, 0644))
+
+		calls := 0
+		require.NoError(t, w.spool.replay(func(alert *alert) bool {
+			calls++
+			return false
+		}))
+		require.Equal(t, 1, calls, "the replay went on after a failed delivery")
+		replayed, err := os.ReadFile(spoolFile)
+		require.NoError(t, err)
+		require.Equal(t, spooled, replayed)
+	})
+}
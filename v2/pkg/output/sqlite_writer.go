@@ -0,0 +1,121 @@
+package output
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	// pure go sqlite driver, the releases are built without cgo
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDriverName is the database/sql driver registered by modernc.org/sqlite
+const sqliteDriverName = "sqlite"
+
+// sqliteBatchSize is the number of results inserted per transaction
+const sqliteBatchSize = 100
+
+const createResultsTable = `CREATE TABLE IF NOT EXISTS results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	template_id TEXT NOT NULL,
+	severity TEXT,
+	host TEXT,
+	matched_at TEXT,
+	timestamp TEXT,
+	raw_json TEXT
+)`
+
+const insertResult = `INSERT INTO results (template_id, severity, host, matched_at, timestamp, raw_json) VALUES (?, ?, ?, ?, ?, ?)`
+
+// sqliteWriter appends results to the results table of a sqlite database,
+// batching the inserts in transactions for throughput.
+type sqliteWriter struct {
+	mu      sync.Mutex
+	db      *sql.DB
+	tx      *sql.Tx
+	insert  *sql.Stmt
+	pending int
+}
+
+// newSQLiteWriter opens the sqlite database creating the results table if required
+func newSQLiteWriter(path string) (*sqliteWriter, error) {
+	db, err := sql.Open(sqliteDriverName, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open sqlite database")
+	}
+	if _, err := db.Exec(createResultsTable); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "could not create sqlite results table")
+	}
+	return &sqliteWriter{db: db}, nil
+}
+
+// Write inserts the result along with its json representation
+func (s *sqliteWriter) Write(event *ResultEvent, rawJSON []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return errors.Wrap(err, "could not begin sqlite transaction")
+		}
+		insert, err := tx.Prepare(insertResult)
+		if err != nil {
+			_ = tx.Rollback()
+			return errors.Wrap(err, "could not prepare sqlite insert")
+		}
+		s.tx, s.insert = tx, insert
+	}
+	_, err := s.insert.Exec(
+		event.TemplateID,
+		event.Info.SeverityHolder.Severity.String(),
+		event.Host,
+		event.Matched,
+		event.Timestamp.Format(time.RFC3339Nano),
+		string(rawJSON),
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not insert sqlite result")
+	}
+	s.pending++
+	if s.pending >= sqliteBatchSize {
+		return s.commit()
+	}
+	return nil
+}
+
+// Flush commits the pending inserts
+func (s *sqliteWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commit()
+}
+
+// commit commits the current transaction if any
+func (s *sqliteWriter) commit() error {
+	if s.tx == nil {
+		return nil
+	}
+	s.insert.Close()
+	err := s.tx.Commit()
+	s.tx, s.insert, s.pending = nil, nil, 0
+	if err != nil {
+		return errors.Wrap(err, "could not commit sqlite transaction")
+	}
+	return nil
+}
+
+// Close commits the pending inserts and closes the database
+func (s *sqliteWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.commit()
+	if closeErr := s.db.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
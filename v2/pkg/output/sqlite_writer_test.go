@@ -0,0 +1,64 @@
+package output
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+func TestSQLiteWriter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "results.db")
+	sqliteOutput, err := newSQLiteWriter(path)
+	require.NoError(t, err)
+
+	w := newTestWriter(ts.URL)
+	w.sqliteOutput = sqliteOutput
+
+	events := []*ResultEvent{
+		{TemplateID: "git-config", Host: "https://example.com", Matched: "https://example.com/.git/config", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Medium}}},
+		{TemplateID: "tech-detect", Host: "https://example.org", Matched: "https://example.org", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Info}}},
+	}
+	for i := 0; i < sqliteBatchSize; i++ {
+		events = append(events, &ResultEvent{TemplateID: fmt.Sprintf("template-%d", i), Host: "https://example.net"})
+	}
+	for _, event := range events {
+		require.NoError(t, w.Write(event))
+	}
+	require.Equal(t, 2, sqliteOutput.pending, "inserts were not batched")
+	require.NoError(t, w.Flush())
+	require.NoError(t, sqliteOutput.Close())
+
+	db, err := sql.Open(sqliteDriverName, path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query("SELECT template_id, severity, host, matched_at, raw_json FROM results ORDER BY id")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		var templateID, severity, host, matchedAt, rawJSON string
+		require.NoError(t, rows.Scan(&templateID, &severity, &host, &matchedAt, &rawJSON))
+		event := events[count]
+		require.Equal(t, event.TemplateID, templateID)
+		require.Equal(t, event.Info.SeverityHolder.Severity.String(), severity)
+		require.Equal(t, event.Host, host)
+		require.Equal(t, event.Matched, matchedAt)
+		require.Contains(t, rawJSON, fmt.Sprintf(`"template-id":%q`, event.TemplateID))
+		count++
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, len(events), count)
+}
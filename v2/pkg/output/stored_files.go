@@ -0,0 +1,134 @@
+package output
+
+import (
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	fileutil "github.com/projectdiscovery/utils/file"
+	"go.uber.org/multierr"
+)
+
+// defaultMaxOpenStoredFiles is the default number of stored response files kept open
+const defaultMaxOpenStoredFiles = 64
+
+// storedFile is an open stored response file shared by the writes to it.
+// The file is nil once closed by the pool.
+type storedFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// storedFilePool keeps the stored response files open across writes
+// instead of opening them for every response. Once the maximum number
+// of files is open, one of them is closed to open the next one.
+type storedFilePool struct {
+	mu      sync.Mutex
+	files   map[string]*storedFile
+	maxOpen int
+	// opened is the number of files opened, for tests
+	opened int
+}
+
+// newStoredFilePool returns a pool keeping up to maxOpen files open
+func newStoredFilePool(maxOpen int) *storedFilePool {
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenStoredFiles
+	}
+	return &storedFilePool{files: make(map[string]*storedFile), maxOpen: maxOpen}
+}
+
+// write appends the data to the file at path in a single write, so
+// concurrent writes to the same file are never interleaved.
+func (p *storedFilePool) write(path string, data []byte) error {
+	for {
+		file, err := p.get(path)
+		if err != nil {
+			return err
+		}
+		file.mu.Lock()
+		if file.file == nil {
+			// closed by the pool meanwhile, open it again
+			file.mu.Unlock()
+			continue
+		}
+		_, err = file.file.Write(data)
+		file.mu.Unlock()
+		return err
+	}
+}
+
+// get returns the open file at path, opening it if needed
+func (p *storedFilePool) get(path string) (*storedFile, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if file, ok := p.files[path]; ok {
+		return file, nil
+	}
+	if len(p.files) >= p.maxOpen {
+		for evicted, file := range p.files {
+			file.mu.Lock()
+			_ = file.file.Close()
+			file.file = nil
+			file.mu.Unlock()
+			delete(p.files, evicted)
+			break
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	p.opened++
+	file := &storedFile{file: f}
+	p.files[path] = file
+	return file, nil
+}
+
+// close closes all the open files
+func (p *storedFilePool) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for path, file := range p.files {
+		file.mu.Lock()
+		if closeErr := file.file.Close(); closeErr != nil {
+			err = multierr.Append(err, closeErr)
+		}
+		file.file = nil
+		file.mu.Unlock()
+		delete(p.files, path)
+	}
+	return err
+}
+
+// storedFolder is a folder of stored responses, created once
+type storedFolder struct {
+	mu      sync.Mutex
+	created bool
+}
+
+// createStoredFolder creates the folder of stored responses at path once,
+// the concurrent writes to it waiting for its creation. A failed creation
+// is retried by the next write.
+func (w *StandardWriter) createStoredFolder(path string) error {
+	value, _ := w.storedFolders.LoadOrStore(path, &storedFolder{})
+	folder := value.(*storedFolder)
+	folder.mu.Lock()
+	defer folder.mu.Unlock()
+
+	if folder.created {
+		return nil
+	}
+	create := w.createFolder
+	if create == nil {
+		create = fileutil.CreateFolder
+	}
+	if err := create(path); err != nil {
+		return errors.Wrapf(err, "could not create stored responses folder %s", path)
+	}
+	folder.created = true
+	return nil
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+// maxSummaryTemplates is the number of most frequent templates in the scan summary
+const maxSummaryTemplates = 10
+
+// templateCounter counts the findings per template id. The zero value
+// is ready to use.
+type templateCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// add counts a finding of the template
+func (c *templateCounter) add(templateID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[templateID]++
+}
+
+// top returns the limit templates with the most findings, ordered by
+// the number of findings and then by template id.
+func (c *templateCounter) top(limit int) []templateCount {
+	c.mu.Lock()
+	counts := make([]templateCount, 0, len(c.counts))
+	for templateID, count := range c.counts {
+		counts = append(counts, templateCount{TemplateID: templateID, Count: count})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].TemplateID < counts[j].TemplateID
+	})
+	if len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
+
+// templateCount is the number of findings of a template
+type templateCount struct {
+	TemplateID string `json:"template-id"`
+	Count      int    `json:"count"`
+}
+
+// scanSummary is the context of the `scan.summary` event
+type scanSummary struct {
+	Total        int             `json:"total"`
+	Severities   map[string]int  `json:"severities"`
+	TopTemplates []templateCount `json:"top-templates"`
+	StartTime    time.Time       `json:"start-time"`
+	EndTime      time.Time       `json:"end-time"`
+}
+
+// scanSummary returns the summary of the findings written so far
+func (w *StandardWriter) scanSummary() scanSummary {
+	summary := scanSummary{
+		Severities:   make(map[string]int),
+		TopTemplates: w.templateCounts.top(maxSummaryTemplates),
+		EndTime:      time.Now(),
+	}
+	for value, count := range w.Counts() {
+		name := value.String()
+		if name == "" {
+			name = severity.Unknown.String()
+		}
+		summary.Severities[name] += count
+		summary.Total += count
+	}
+	w.scanMutex.Lock()
+	summary.StartTime = w.scanStartTime
+	w.scanMutex.Unlock()
+	return summary
+}
+
+// sendScanSummary triggers the `scan.summary` event on the webhook
+func (w *StandardWriter) sendScanSummary() {
+	data, err := jsonEncoder.Marshal(w.scanSummary())
+	if err != nil {
+		gologger.Warning().Msgf("Could not marshal scan summary: %s\n", err)
+		return
+	}
+	resp, err := w.sendAstraEvent(context.Background(), "scan.summary", data, "")
+	if err != nil {
+		gologger.Warning().Msgf("Could not send scan summary event: %s\n", err)
+		return
+	}
+	resp.Body.Close()
+
+	w.logVerbose("Request status received -> %s for scan summary\n", resp.Status)
+}
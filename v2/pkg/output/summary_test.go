@@ -0,0 +1,81 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestStandardWriterScanSummary(t *testing.T) {
+	var mu sync.Mutex
+	var summary json.RawMessage
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Meta    AstraMeta       `json:"meta"`
+			Context json.RawMessage `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Meta.Event == "scan.summary" {
+			mu.Lock()
+			summary = body.Context
+			mu.Unlock()
+		}
+	}))
+
+	w, err := NewStandardWriter(&types.Options{JSONL: true})
+	require.NoError(t, err)
+	write := func(templateID string, value severity.Severity, count int) {
+		for i := 0; i < count; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: templateID, Info: model.Info{SeverityHolder: severity.Holder{Severity: value}}}))
+		}
+	}
+	write("git-config", severity.High, 3)
+	write("log4j-rce", severity.Critical, 1)
+	write("tech-detect", severity.Info, 3)
+	write("unrated", severity.Undefined, 1)
+	for i := 0; i < maxSummaryTemplates; i++ {
+		write(fmt.Sprintf("low-%02d", i), severity.Low, 1)
+	}
+	w.Close()
+
+	var decoded scanSummary
+	require.NoError(t, json.Unmarshal(summary, &decoded), "scan summary event wasn't sent")
+	require.Equal(t, 18, decoded.Total)
+	require.Equal(t, map[string]int{"critical": 1, "high": 3, "low": maxSummaryTemplates, "info": 3, "unknown": 1}, decoded.Severities)
+	require.Len(t, decoded.TopTemplates, maxSummaryTemplates)
+	require.Equal(t, []templateCount{
+		{TemplateID: "git-config", Count: 3},
+		{TemplateID: "tech-detect", Count: 3},
+		{TemplateID: "log4j-rce", Count: 1},
+		{TemplateID: "low-00", Count: 1},
+	}, decoded.TopTemplates[:4], "templates weren't ordered by the number of findings")
+	require.False(t, decoded.StartTime.IsZero())
+	require.False(t, decoded.EndTime.Before(decoded.StartTime))
+
+	t.Run("NoFindings", func(t *testing.T) {
+		summary = nil
+		w, err := NewStandardWriter(&types.Options{JSONL: true})
+		require.NoError(t, err)
+		w.Close()
+
+		require.JSONEq(t, `{"total":0,"severities":{},"top-templates":[]}`, stripTimes(t, summary))
+	})
+}
+
+// stripTimes returns the scan summary without its start and end times
+func stripTimes(t *testing.T, data json.RawMessage) string {
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	delete(fields, "start-time")
+	delete(fields, "end-time")
+	stripped, _ := json.Marshal(fields)
+	return string(stripped)
+}
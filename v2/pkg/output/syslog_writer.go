@@ -0,0 +1,95 @@
+//go:build !windows && !plan9
+
+package output
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// syslogFacilities are the syslog facilities by name
+var syslogFacilities = map[string]syslog.Priority{
+	"kern": syslog.LOG_KERN, "user": syslog.LOG_USER, "mail": syslog.LOG_MAIL, "daemon": syslog.LOG_DAEMON,
+	"auth": syslog.LOG_AUTH, "syslog": syslog.LOG_SYSLOG, "lpr": syslog.LOG_LPR, "news": syslog.LOG_NEWS,
+	"uucp": syslog.LOG_UUCP, "cron": syslog.LOG_CRON, "authpriv": syslog.LOG_AUTHPRIV, "ftp": syslog.LOG_FTP,
+	"local0": syslog.LOG_LOCAL0, "local1": syslog.LOG_LOCAL1, "local2": syslog.LOG_LOCAL2, "local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4, "local5": syslog.LOG_LOCAL5, "local6": syslog.LOG_LOCAL6, "local7": syslog.LOG_LOCAL7,
+}
+
+// syslogSeverities are the syslog severities by name
+var syslogSeverities = map[string]syslog.Priority{
+	"emerg": syslog.LOG_EMERG, "alert": syslog.LOG_ALERT, "crit": syslog.LOG_CRIT, "err": syslog.LOG_ERR,
+	"warning": syslog.LOG_WARNING, "notice": syslog.LOG_NOTICE, "info": syslog.LOG_INFO, "debug": syslog.LOG_DEBUG,
+}
+
+// syslogWriter writes the formatted results to syslog
+type syslogWriter struct {
+	writer *syslog.Writer
+}
+
+// newSyslogWriter connects to the syslog target, "local" for the local
+// syslog daemon or a udp://host:port or tcp://host:port remote one, to
+// write the results at the facility.severity priority, eg. local0.info.
+func newSyslogWriter(target, priority string) (*syslogWriter, error) {
+	network, address, err := parseSyslogTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	syslogPriority, err := parseSyslogPriority(priority)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := syslog.Dial(network, address, syslogPriority, "nuclei")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not connect to syslog")
+	}
+	return &syslogWriter{writer: writer}, nil
+}
+
+// parseSyslogTarget returns the network and the address of the syslog target
+func parseSyslogTarget(target string) (string, string, error) {
+	if target == "local" {
+		return "", "", nil
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "udp" && parsed.Scheme != "tcp") || parsed.Host == "" {
+		return "", "", fmt.Errorf("invalid syslog target %q: expected local, udp://host:port or tcp://host:port", target)
+	}
+	return parsed.Scheme, parsed.Host, nil
+}
+
+// parseSyslogPriority parses a facility.severity syslog priority, the
+// facility defaults to user and the severity to info when empty.
+func parseSyslogPriority(priority string) (syslog.Priority, error) {
+	facilityName, severityName, _ := strings.Cut(strings.ToLower(priority), ".")
+	if facilityName == "" {
+		facilityName = "user"
+	}
+	if severityName == "" {
+		severityName = "info"
+	}
+	facility, ok := syslogFacilities[facilityName]
+	if !ok {
+		return 0, fmt.Errorf("invalid syslog priority %q: unknown facility %s", priority, facilityName)
+	}
+	severity, ok := syslogSeverities[severityName]
+	if !ok {
+		return 0, fmt.Errorf("invalid syslog priority %q: unknown severity %s", priority, severityName)
+	}
+	return facility | severity, nil
+}
+
+// Write writes the formatted result to syslog
+func (w *syslogWriter) Write(data []byte) error {
+	_, err := w.writer.Write(data)
+	return err
+}
+
+// Close closes the connection to syslog
+func (w *syslogWriter) Close() error {
+	return w.writer.Close()
+}
@@ -0,0 +1,24 @@
+//go:build windows || plan9
+
+package output
+
+import "github.com/projectdiscovery/gologger"
+
+// syslogWriter is a no-op writer as syslog isn't supported on the platform
+type syslogWriter struct{}
+
+// newSyslogWriter returns a no-op writer as syslog isn't supported on the platform
+func newSyslogWriter(target, priority string) (*syslogWriter, error) {
+	gologger.Warning().Msgf("Syslog output is not supported on this platform, results won't be written to syslog\n")
+	return &syslogWriter{}, nil
+}
+
+// Write discards the formatted result
+func (w *syslogWriter) Write(data []byte) error {
+	return nil
+}
+
+// Close does nothing
+func (w *syslogWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,84 @@
+//go:build !windows && !plan9
+
+package output
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogWriter(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	syslogOutput, err := newSyslogWriter("udp://"+listener.LocalAddr().String(), "local0.warning")
+	require.NoError(t, err)
+	defer syslogOutput.Close()
+
+	w := newTestWriter("")
+	w.syslogOutput = syslogOutput
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config", Host: "https://example.com"}))
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "tech-detect", Host: "https://example.com"}))
+
+	var messages []string
+	buffer := make([]byte, 65536)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for len(messages) < 2 {
+		n, _, err := listener.ReadFrom(buffer)
+		require.NoError(t, err)
+		messages = append(messages, string(buffer[:n]))
+	}
+	for i, templateID := range []string{"git-config", "tech-detect"} {
+		// local0 (16) * 8 + warning (4)
+		require.True(t, strings.HasPrefix(messages[i], "<132>"), messages[i])
+		require.Contains(t, messages[i], "nuclei")
+		require.Contains(t, messages[i], `"template-id":"`+templateID+`"`)
+	}
+}
+
+func TestParseSyslogTarget(t *testing.T) {
+	tests := []struct {
+		target  string
+		network string
+		address string
+		err     bool
+	}{
+		{"local", "", "", false},
+		{"udp://syslog.example.com:514", "udp", "syslog.example.com:514", false},
+		{"tcp://10.0.0.1:6514", "tcp", "10.0.0.1:6514", false},
+		{"http://syslog.example.com", "", "", true},
+		{"syslog.example.com:514", "", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.target, func(t *testing.T) {
+			network, address, err := parseSyslogTarget(test.target)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.network, network)
+			require.Equal(t, test.address, address)
+		})
+	}
+}
+
+func TestParseSyslogPriority(t *testing.T) {
+	priority, err := parseSyslogPriority("")
+	require.NoError(t, err)
+	require.Equal(t, 14, int(priority), "default should be user.info")
+
+	priority, err = parseSyslogPriority("LOCAL7.debug")
+	require.NoError(t, err)
+	require.Equal(t, 23*8+7, int(priority))
+
+	_, err = parseSyslogPriority("local9.info")
+	require.ErrorContains(t, err, "unknown facility")
+	_, err = parseSyslogPriority("user.loud")
+	require.ErrorContains(t, err, "unknown severity")
+}
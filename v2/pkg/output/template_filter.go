@@ -0,0 +1,47 @@
+package output
+
+import (
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+// templateFilter drops the results of suppressed templates or, when an
+// allow-list is set, of the templates not in it. Template ids are matched
+// against glob patterns, eg. tech-detect or cve-2021-*.
+type templateFilter struct {
+	suppress []string
+	only     []string
+}
+
+// newTemplateFilter creates a new template filter validating the patterns.
+// It returns nil if no templates are suppressed or allowed.
+func newTemplateFilter(suppress, only []string) (*templateFilter, error) {
+	if len(suppress) == 0 && len(only) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range append(append([]string{}, suppress...), only...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid template id pattern %q", pattern)
+		}
+	}
+	return &templateFilter{suppress: suppress, only: only}, nil
+}
+
+// allowed returns true if the results of the template id should be written
+func (f *templateFilter) allowed(templateID string) bool {
+	if matchesAnyPattern(f.suppress, templateID) {
+		return false
+	}
+	return len(f.only) == 0 || matchesAnyPattern(f.only, templateID)
+}
+
+// matchesAnyPattern returns true if the value matches any of the glob patterns
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+func TestTemplateFilter(t *testing.T) {
+	var mu sync.Mutex
+	var alerts int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		alerts++
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	templateIDs := []string{"tech-detect", "cve-2021-44228", "cve-2021-41773", "cve-2022-22965", "git-config"}
+	tests := []struct {
+		name     string
+		suppress []string
+		only     []string
+		written  []string
+	}{
+		{"Suppress", []string{"tech-detect"}, nil, []string{"cve-2021-44228", "cve-2021-41773", "cve-2022-22965", "git-config"}},
+		{"AllowList", nil, []string{"git-config", "tech-detect"}, []string{"tech-detect", "git-config"}},
+		{"Glob", []string{"cve-2021-*"}, nil, []string{"tech-detect", "cve-2022-22965", "git-config"}},
+		{"AllowListGlob", nil, []string{"cve-*"}, []string{"cve-2021-44228", "cve-2021-41773", "cve-2022-22965"}},
+		{"SuppressWins", []string{"cve-2021-4177?"}, []string{"cve-*"}, []string{"cve-2021-44228", "cve-2022-22965"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := newTemplateFilter(test.suppress, test.only)
+			require.NoError(t, err)
+
+			outputWriter := &testWriteCloser{}
+			w := newTestWriter(ts.URL)
+			w.outputFile = outputWriter
+			w.templateFilter = filter
+			alerts = 0
+
+			var written []string
+			for _, templateID := range templateIDs {
+				require.NoError(t, w.Write(&ResultEvent{TemplateID: templateID}))
+				if filter.allowed(templateID) {
+					written = append(written, templateID)
+				}
+			}
+			require.Equal(t, test.written, written)
+			require.Equal(t, len(test.written), alerts, "suppressed results were sent to the webhook")
+			for _, templateID := range templateIDs {
+				require.Equal(t, contains(test.written, templateID), containsTemplateID(outputWriter.String(), templateID), templateID)
+			}
+		})
+	}
+
+	t.Run("NoFilter", func(t *testing.T) {
+		filter, err := newTemplateFilter(nil, nil)
+		require.NoError(t, err)
+		require.Nil(t, filter)
+	})
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{SuppressTemplateIDs: []string{"cve-[2021"}})
+		require.ErrorContains(t, err, "invalid template id pattern")
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, current := range values {
+		if current == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTemplateID(output, templateID string) bool {
+	return strings.Contains(output, `"template-id":"`+templateID+`"`)
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// TLSCertificate is the certificate of the https server of a result
+type TLSCertificate struct {
+	// Subject is the distinguished name of the subject of the certificate
+	Subject string `json:"subject,omitempty"`
+	// Issuer is the distinguished name of the issuer of the certificate
+	Issuer string `json:"issuer,omitempty"`
+	// NotBefore and NotAfter are the validity bounds of the certificate
+	NotBefore time.Time `json:"not-before"`
+	NotAfter  time.Time `json:"not-after"`
+	// SubjectAN is the list of the dns names and ip addresses the certificate is valid for
+	SubjectAN []string `json:"subject-an,omitempty"`
+}
+
+// NewTLSCertificate returns the details of the certificate of a result
+func NewTLSCertificate(cert *x509.Certificate) *TLSCertificate {
+	details := &TLSCertificate{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore.UTC(),
+		NotAfter:  cert.NotAfter.UTC(),
+	}
+	details.SubjectAN = append(details.SubjectAN, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		details.SubjectAN = append(details.SubjectAN, ip.String())
+	}
+	return details
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTLSCertificate(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	cert := ts.Certificate()
+	details := NewTLSCertificate(cert)
+	require.Equal(t, cert.Subject.String(), details.Subject)
+	require.Equal(t, cert.Issuer.String(), details.Issuer)
+	require.True(t, cert.NotAfter.Equal(details.NotAfter))
+	require.True(t, cert.NotBefore.Equal(details.NotBefore))
+	require.Equal(t, []string{"example.com", "*.example.com", "127.0.0.1", "::1"}, details.SubjectAN)
+}
+
+func TestStandardWriterTLS(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	var alert struct {
+		Context ResultEvent `json:"context"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&alert)
+	}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	certificate := NewTLSCertificate(tlsServer.Certificate())
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "expired-ssl", Host: tlsServer.URL, TLS: certificate}))
+
+	var written ResultEvent
+	require.NoError(t, json.Unmarshal([]byte(outputFile.String()), &written))
+	require.Equal(t, certificate, written.TLS, "certificate didn't round-trip through the output")
+	require.Equal(t, certificate, alert.Context.TLS, "certificate didn't round-trip through the webhook")
+
+	t.Run("ECS", func(t *testing.T) {
+		data, err := w.formatECS(&ResultEvent{TemplateID: "expired-ssl", TLS: certificate})
+		require.NoError(t, err)
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &document))
+		server := document["tls"].(map[string]interface{})["server"].(map[string]interface{})
+		require.Equal(t, certificate.Issuer, server["issuer"])
+		require.Equal(t, ecsTime(certificate.NotAfter), server["not_after"])
+		require.Equal(t, []interface{}{"example.com", "*.example.com", "127.0.0.1", "::1"}, server["x509"].(map[string]interface{})["alternative_names"])
+	})
+
+	t.Run("Omitted", func(t *testing.T) {
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.outputFile = outputFile
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+		require.NotContains(t, outputFile.String(), `"tls"`)
+	})
+}
@@ -0,0 +1,36 @@
+package output
+
+import "github.com/pkg/errors"
+
+// TransformFunc transforms a result event before it is written. It returns
+// the event to write, nil to drop the event or an error to abort the write.
+type TransformFunc func(*ResultEvent) (*ResultEvent, error)
+
+// TransformWriter is a writer applying a transform function to each event
+// before writing it with the wrapped writer, eg. to add environment labels
+// or to scrub sensitive data centrally.
+//
+// Failure events are written as is, the other methods are passed through.
+type TransformWriter struct {
+	Writer
+	transform TransformFunc
+}
+
+var _ Writer = &TransformWriter{}
+
+// NewTransformWriter creates a new writer transforming the events written to writer.
+func NewTransformWriter(writer Writer, transform TransformFunc) *TransformWriter {
+	return &TransformWriter{Writer: writer, transform: transform}
+}
+
+// Write transforms the event and writes it to the wrapped writer unless dropped
+func (t *TransformWriter) Write(event *ResultEvent) error {
+	transformed, err := t.transform(event)
+	if err != nil {
+		return errors.Wrap(err, "could not transform event")
+	}
+	if transformed == nil {
+		return nil
+	}
+	return t.Writer.Write(transformed)
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformWriter(t *testing.T) {
+	t.Run("Enrichment", func(t *testing.T) {
+		mock := &mockWriter{}
+		writer := NewTransformWriter(mock, func(event *ResultEvent) (*ResultEvent, error) {
+			if event.Metadata == nil {
+				event.Metadata = make(map[string]interface{})
+			}
+			event.Metadata["environment"] = "staging"
+			event.Request = strings.ReplaceAll(event.Request, "secret-token", "[redacted]")
+			return event, nil
+		})
+
+		require.NoError(t, writer.Write(&ResultEvent{TemplateID: "test", Request: "Authorization: secret-token"}))
+		require.Len(t, mock.events, 1)
+		require.Equal(t, "staging", mock.events[0].Metadata["environment"])
+		require.Equal(t, "Authorization: [redacted]", mock.events[0].Request)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		mock := &mockWriter{}
+		writer := NewTransformWriter(mock, func(event *ResultEvent) (*ResultEvent, error) {
+			if event.TemplateID == "noisy" {
+				return nil, nil
+			}
+			return event, nil
+		})
+
+		require.NoError(t, writer.Write(&ResultEvent{TemplateID: "noisy"}))
+		require.NoError(t, writer.Write(&ResultEvent{TemplateID: "test"}))
+		require.Len(t, mock.events, 1)
+		require.Equal(t, "test", mock.events[0].TemplateID)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock := &mockWriter{}
+		writer := NewTransformWriter(mock, func(event *ResultEvent) (*ResultEvent, error) {
+			return nil, errors.New("lookup failed")
+		})
+
+		err := writer.Write(&ResultEvent{TemplateID: "test"})
+		require.ErrorContains(t, err, "could not transform event: lookup failed")
+		require.Empty(t, mock.events)
+	})
+
+	t.Run("PassThrough", func(t *testing.T) {
+		mock := &mockWriter{}
+		writer := NewTransformWriter(mock, func(event *ResultEvent) (*ResultEvent, error) { return nil, nil })
+
+		require.NoError(t, writer.WriteFailure(InternalEvent{"template-id": "test"}))
+		writer.Request("test", "https://example.com", "http", nil)
+		writer.WriteError("test", "https://example.com", errors.New("could not connect"))
+		writer.WriteStoreDebugData("example.com", "test", "http", "data")
+		require.NoError(t, writer.Flush())
+		writer.Close()
+
+		require.Len(t, mock.failures, 1)
+		require.Equal(t, 1, mock.requests)
+		require.Equal(t, 1, mock.errors)
+		require.Equal(t, 1, mock.debugData)
+		require.Equal(t, 1, mock.flushes)
+		require.True(t, mock.closed)
+	})
+}
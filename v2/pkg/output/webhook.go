@@ -0,0 +1,464 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/gologger"
+	"golang.org/x/time/rate"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+)
+
+// defaultWebhookRetryDelay is the base delay between webhook delivery attempts.
+// The delay grows linearly with the number of attempts made.
+const defaultWebhookRetryDelay = time.Second
+
+// webhookTimeout is the maximum time a webhook request can take, so a
+// hung webhook doesn't hold a worker of the alert queue forever.
+const webhookTimeout = 30 * time.Second
+
+// validateWebhookURL validates that the webhook url is an absolute http or
+// https url, or a unix:// url of the unix domain socket the receiver listens on.
+func validateWebhookURL(webhookURL string) error {
+	if webhookURL == "" {
+		return errors.New("webhook url is empty")
+	}
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid webhook url %q", webhookURL)
+	}
+	if parsed.Scheme == "unix" && parsed.Host == "" && parsed.Path != "" {
+		return nil
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook url %q: expected an absolute http or https url or a unix:///path/to/socket url", webhookURL)
+	}
+	return nil
+}
+
+// deliverAlert delivers a formatted result as an alert to the webhook,
+// spooling it for a later replay if the delivery failed. The alert is
+// delivered again when the webhook acknowledges it with a retry after.
+// It is published with the alert transport instead if any.
+func (w *StandardWriter) deliverAlert(alert *alert) {
+	w.resolveAttachments(alert)
+	if w.alertTransport != nil {
+		w.publishAlert(alert)
+		return
+	}
+	w.logVerbose("Raising alert for -> %s\n", alert.templateURL)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := w.sendAlert(alert)
+		if err != nil {
+			gologger.Warning().Msgf("Could not send alert: %s\n", err)
+			w.spoolAlert(alert)
+			return
+		}
+		ack, ackErr := readAlertAck(resp)
+		if ackErr != nil {
+			w.logVerbose("Ignoring webhook acknowledgement for %s: %s\n", alert.templateURL, ackErr)
+		}
+		w.logVerbose("Request status received -> %s for alert\n", resp.Status)
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			if shouldRetryStatus(resp.StatusCode) {
+				w.spoolAlert(alert)
+			}
+			return
+		}
+		if delay := ack.retryDelay(w.retryAfterUnit); delay > 0 {
+			if attempt >= maxAlertAckRetries {
+				gologger.Warning().Msgf("Webhook asked to retry alert for %s %d times, spooling it\n", alert.templateURL, attempt+1)
+				w.spoolAlert(alert)
+				return
+			}
+			w.logVerbose("Webhook asked to retry alert for %s after %s\n", alert.templateURL, delay)
+			select {
+			case <-time.After(delay):
+				continue
+			case <-w.deliveryContext().Done():
+				w.spoolAlert(alert)
+				return
+			}
+		}
+		if ack.rejected() {
+			gologger.Warning().Msgf("Webhook rejected alert for %s\n", alert.templateURL)
+			return
+		}
+		w.metrics.alertsSent.Add(1)
+		if ack.ID != "" {
+			w.logVerbose("Alert for %s accepted by webhook with id %s\n", alert.templateURL, ack.ID)
+		}
+		return
+	}
+}
+
+// sendAlert posts the alert to its webhook, falling back to the backup
+// webhook if any when the delivery to the webhook failed.
+func (w *StandardWriter) sendAlert(alert *alert) (*http.Response, error) {
+	body := alert.body
+	if body == nil {
+		var err error
+		if body, err = w.astraRequestBody(alert.eventName(), alert.context, alert.idempotencyKey); err != nil {
+			return nil, err
+		}
+	}
+	webhookURL := w.AstraWebhook
+	if alert.webhookURL != "" {
+		webhookURL = alert.webhookURL
+	}
+	resp, err := w.postWebhook(w.deliveryContext(), webhookURL, body, alert.idempotencyKey)
+	if w.backupWebhook == "" || !deliveryFailed(resp, err) {
+		return resp, err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	gologger.Warning().Msgf("Could not send alert to webhook, trying backup webhook\n")
+	resp, err = w.postWebhook(w.deliveryContext(), w.backupWebhook, body, alert.idempotencyKey)
+	if !deliveryFailed(resp, err) {
+		w.metrics.backupAlertsSent.Add(1)
+		w.logVerbose("Alert delivered to backup webhook for -> %s\n", alert.templateURL)
+	}
+	return resp, err
+}
+
+// newSeverityWebhooks expands and validates the webhook urls the alerts
+// are routed to by severity.
+func newSeverityWebhooks(webhooks map[severity.Severity]string) (map[severity.Severity]string, error) {
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+	expanded := make(map[severity.Severity]string, len(webhooks))
+	for value, webhookURL := range webhooks {
+		webhookURL, err := expandEnvPlaceholders(webhookURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not expand %s webhook url", value)
+		}
+		if err := validateWebhookURL(webhookURL); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s webhook url", value)
+		}
+		expanded[value] = webhookURL
+	}
+	return expanded, nil
+}
+
+// deliveryFailed returns true if the webhook couldn't be reached or
+// failed with a status the delivery should be retried on.
+func deliveryFailed(resp *http.Response, err error) bool {
+	return err != nil || shouldRetryStatus(resp.StatusCode)
+}
+
+// spoolAlert persists the undelivered alert to the spool if any
+func (w *StandardWriter) spoolAlert(alert *alert) {
+	if w.spool == nil {
+		return
+	}
+	if err := w.spool.add(alert); err != nil {
+		gologger.Warning().Msgf("Could not spool alert: %s\n", err)
+	}
+}
+
+// sendAstraEvent wraps the context in an astra request for the event
+// and posts it to the webhook url.
+//
+// idempotencyKey, if not empty, is sent in the meta as well as in the
+// Idempotency-Key header so the receiver can deduplicate retried deliveries.
+func (w *StandardWriter) sendAstraEvent(ctx context.Context, event string, context json.RawMessage, idempotencyKey string) (*http.Response, error) {
+	postBody, err := w.astraRequestBody(event, context, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return w.postWebhook(ctx, w.AstraWebhook, postBody, idempotencyKey)
+}
+
+// postWebhook posts the body to the webhook url retrying on network
+// errors and server side failures. The response of the last attempt
+// is returned once retries are exhausted.
+func (w *StandardWriter) postWebhook(ctx context.Context, url string, body []byte, idempotencyKey string) (*http.Response, error) {
+	client := w.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if w.alertFormat == AlertFormatMsgpack {
+		converted, err := jsonToMsgpack(body)
+		if err != nil {
+			return nil, err
+		}
+		body = converted
+	}
+	// The signature is computed over the payload before any compression
+	var signature string
+	if w.webhookSecret != "" {
+		signature = signPayload(body, w.webhookSecret)
+	}
+	var contentEncoding string
+	if w.gzipThreshold > 0 && len(body) > w.gzipThreshold {
+		compressed, err := gzipPayload(body)
+		if err != nil {
+			return nil, err
+		}
+		body = compressed
+		contentEncoding = "gzip"
+	}
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt <= w.webhookRetries; attempt++ {
+		if attempt > 0 {
+			w.metrics.webhookRetries.Add(1)
+			select {
+			case <-time.After(time.Duration(attempt) * w.webhookRetryDelay):
+			case <-ctx.Done():
+				w.metrics.webhookFailures.Add(1)
+				return nil, ctx.Err()
+			}
+		}
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create webhook request")
+		}
+		req.Header.Set("Content-Type", alertContentType(w.alertFormat))
+		req.Header.Set(envelopeVersionHeader, w.envelopeVersionName())
+		w.setAuthHeader(req)
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		if signature != "" {
+			req.Header.Set("X-Signature", signature)
+		}
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+
+		resp, err = client.Do(req)
+		if err == nil && !shouldRetryStatus(resp.StatusCode) {
+			if resp.StatusCode >= http.StatusBadRequest {
+				w.metrics.webhookFailures.Add(1)
+			}
+			return resp, nil
+		}
+		if err == nil && attempt < w.webhookRetries {
+			resp.Body.Close()
+		}
+	}
+	w.metrics.webhookFailures.Add(1)
+	return resp, err
+}
+
+// setAuthHeader sets the webhook token in the configured auth header
+// of the request, prefixed with the auth scheme if any.
+func (w *StandardWriter) setAuthHeader(req *http.Request) {
+	if w.authHeader == "" || w.authToken == "" {
+		return
+	}
+	value := w.authToken
+	if w.authScheme != "" {
+		value = w.authScheme + " " + value
+	}
+	req.Header.Set(w.authHeader, value)
+}
+
+// deliveryContext returns the context of the alert deliveries,
+// cancelled once the writer gives up on delivering them.
+func (w *StandardWriter) deliveryContext() context.Context {
+	if w.deliveryCtx == nil {
+		return context.Background()
+	}
+	return w.deliveryCtx
+}
+
+// gzipPayload returns the gzip compressed payload
+func gzipPayload(payload []byte) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	if _, err := gzipWriter.Write(payload); err != nil {
+		return nil, errors.Wrap(err, "could not compress webhook payload")
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, errors.Wrap(err, "could not compress webhook payload")
+	}
+	return buffer.Bytes(), nil
+}
+
+// signPayload returns the hex encoded HMAC-SHA256 of the payload using secret
+func signPayload(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// shouldRetryStatus returns true if a webhook delivery with the
+// status code should be retried.
+func shouldRetryStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// idempotencyKey returns a stable key identifying the result event,
+// identical across all delivery attempts of the event.
+func idempotencyKey(event *ResultEvent) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		event.TemplateID,
+		event.Host,
+		event.Matched,
+		event.Timestamp.Format(time.RFC3339Nano),
+	}, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+// rawIdempotencyKey returns a stable key identifying a raw json event
+func rawIdempotencyKey(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// wrapTransport returns the base transport logging the requests in dry-run
+// mode and limiting their rate with the limiter if any. A nil transport is
+// returned when there is nothing to wrap so the client uses the default one.
+func wrapTransport(base http.RoundTripper, dryRun bool, limiter *rate.Limiter) http.RoundTripper {
+	if dryRun {
+		base = &dryRunTransport{}
+	}
+	if limiter != nil {
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		base = &rateLimitedTransport{limiter: limiter, base: base}
+	}
+	return base
+}
+
+// unixSocketTransport is a http transport sending the requests to unix://
+// urls over the unix domain socket at their path, and the other requests
+// with the base transport.
+type unixSocketTransport struct {
+	base       http.RoundTripper
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+// RoundTrip sends the request over the unix domain socket of the url if any
+func (t *unixSocketTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "unix" {
+		base := t.base
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		return base.RoundTrip(req)
+	}
+	socketRequest := req.Clone(req.Context())
+	socketRequest.URL = &url.URL{Scheme: "http", Host: "localhost", Path: "/"}
+	socketRequest.Host = "localhost"
+	return t.socketTransport(req.URL.Path).RoundTrip(socketRequest)
+}
+
+// socketTransport returns the transport dialing the unix domain socket
+func (t *unixSocketTransport) socketTransport(socketPath string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if transport, ok := t.transports[socketPath]; ok {
+		return transport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+	}
+	if t.transports == nil {
+		t.transports = make(map[string]*http.Transport)
+	}
+	t.transports[socketPath] = transport
+	return transport
+}
+
+// statusTLSConfig returns the tls configuration of the status api client
+// trusting the certificates of the ca bundle on top of the system ones.
+func statusTLSConfig(caBundle string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify, //nolint:gosec // explicitly requested for self-signed clusters
+	}
+	if caBundle == "" {
+		return tlsConfig, nil
+	}
+	data, err := os.ReadFile(caBundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read status api ca bundle")
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("invalid status api ca bundle %q: no pem certificates found", caBundle)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// dryRunTransport is a http transport logging the requests instead of sending them.
+type dryRunTransport struct{}
+
+// RoundTrip logs the request along with its payload and returns an empty successful response
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, errors.Wrap(err, "could not read request body")
+		}
+		req.Body.Close()
+	}
+	gologger.Info().Msgf("[dry-run] %s %s %s\n", req.Method, req.URL, body)
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+// rateLimitedTransport is a http transport limiting the rate of the requests
+// sent through it, waiting for the request context while the limit is exceeded.
+type rateLimitedTransport struct {
+	limiter *rate.Limiter
+	base    http.RoundTripper
+}
+
+// RoundTrip waits for the rate limiter before sending the request with the base transport
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, errors.Wrap(err, "could not wait for rate limit")
+	}
+	return t.base.RoundTrip(req)
+}
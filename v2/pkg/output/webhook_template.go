@@ -0,0 +1,68 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+)
+
+// webhookTemplateData is the data available to webhook payload templates
+type webhookTemplateData struct {
+	// Meta is the astra meta of the alert
+	Meta AstraMeta
+	// Event is the result the alert is raised for
+	Event *ResultEvent
+}
+
+// webhookTemplateFuncs are the helper functions available to webhook payload templates
+var webhookTemplateFuncs = template.FuncMap{
+	// json encodes a value as json, e.g. {{ json .Event.Host }}
+	"json": func(value interface{}) (string, error) {
+		data, err := jsonEncoder.Marshal(value)
+		return string(data), err
+	},
+}
+
+// loadWebhookTemplate reads and parses the webhook payload template file
+func loadWebhookTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read webhook template")
+	}
+	return parseWebhookTemplate(string(data))
+}
+
+// parseWebhookTemplate parses a webhook payload template and validates it
+// by rendering a sample event, so unknown fields are reported at startup
+// rather than on the first alert.
+func parseWebhookTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse webhook template")
+	}
+	sample := &ResultEvent{
+		Info:        model.Info{Classification: &model.Classification{}},
+		Interaction: &server.Interaction{},
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, webhookTemplateData{Event: sample}); err != nil {
+		return nil, errors.Wrap(err, "invalid webhook template")
+	}
+	return tmpl, nil
+}
+
+// renderAlert renders the alert payload for the event using the webhook template
+func (w *StandardWriter) renderAlert(event *ResultEvent, alertEvent, idempotencyKey string) ([]byte, error) {
+	meta := w.AstraMeta
+	meta.Event = alertEvent
+	meta.IdempotencyKey = idempotencyKey
+
+	buffer := &bytes.Buffer{}
+	if err := w.webhookTemplate.Execute(buffer, webhookTemplateData{Meta: meta, Event: event}); err != nil {
+		return nil, errors.Wrap(err, "could not render webhook template")
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+)
+
+const testWebhookTemplate = `{
+  "scan": {{ json .Meta.ScanId }},
+  "title": {{ json .Event.Info.Name }},
+  "severity": {{ json .Event.Info.SeverityHolder.Severity.String }},
+  "url": {{ json .Event.Matched }}
+}`
+
+func TestWebhookTemplate(t *testing.T) {
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	tmpl, err := parseWebhookTemplate(testWebhookTemplate)
+	require.NoError(t, err)
+
+	w := newTestWriter(ts.URL)
+	w.AstraMeta = AstraMeta{ScanId: "scan-id"}
+	w.webhookTemplate = tmpl
+
+	event := &ResultEvent{
+		TemplateID: "git-config",
+		Info:       model.Info{Name: "Git Config Disclosure", SeverityHolder: severity.Holder{Severity: severity.Medium}},
+		Matched:    "https://example.com/.git/config",
+	}
+	require.NoError(t, w.Write(event))
+	require.JSONEq(t, `{"scan":"scan-id","title":"Git Config Disclosure","severity":"medium","url":"https://example.com/.git/config"}`, string(body))
+}
+
+func TestWebhookTemplateValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		err      string
+	}{
+		{"Syntax", `{"title": {{ json .Event.Info.Name }`, "could not parse webhook template"},
+		{"UnknownField", `{"title": {{ json .Event.Title }}}`, "invalid webhook template"},
+		{"UnknownFunction", `{"title": {{ yaml .Event.Info.Name }}}`, "could not parse webhook template"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseWebhookTemplate(test.template)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+
+	t.Run("Interaction", func(t *testing.T) {
+		_, err := parseWebhookTemplate(`{"protocol": {{ json .Event.Interaction.Protocol }}}`)
+		require.NoError(t, err)
+	})
+
+	t.Run("NewStandardWriter", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+
+		templatePath := filepath.Join(t.TempDir(), "webhook.tmpl")
+		require.NoError(t, os.WriteFile(templatePath, []byte(`{{ .Event.Unknown }}`), 0644))
+		_, err := NewStandardWriter(&types.Options{WebhookTemplate: templatePath})
+		require.ErrorContains(t, err, "invalid webhook template")
+	})
+}
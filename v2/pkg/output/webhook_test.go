@@ -0,0 +1,689 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/gologger/levels"
+	"github.com/projectdiscovery/gologger/writer"
+	"github.com/projectdiscovery/nuclei/v2/internal/colorizer"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model"
+	"github.com/projectdiscovery/nuclei/v2/pkg/model/types/severity"
+	"github.com/projectdiscovery/nuclei/v2/pkg/types"
+	"golang.org/x/time/rate"
+)
+
+func TestWebhookIdempotencyKey(t *testing.T) {
+	var keys []string
+	var bodies []AstraAlertRequest
+	failFirst := true
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body AstraAlertRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		bodies = append(bodies, body)
+		if failFirst {
+			failFirst = false
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	w.webhookRetries = 1
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Host: "https://example.com", Matched: "https://example.com/admin"}))
+
+	require.Len(t, keys, 2, "could not retry failed delivery")
+	require.NotEmpty(t, keys[0])
+	require.Equal(t, keys[0], keys[1], "idempotency key changed between retries")
+	require.Equal(t, keys[0], bodies[0].Meta.IdempotencyKey)
+	require.Equal(t, keys[1], bodies[1].Meta.IdempotencyKey)
+
+	t.Run("DistinctEvents", func(t *testing.T) {
+		keys = nil
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Host: "https://example.com", Matched: "https://example.com/login"}))
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test", Host: "https://example.com", Matched: "https://example.com/admin"}))
+		require.Len(t, keys, 2)
+		require.NotEqual(t, keys[0], keys[1])
+	})
+}
+
+func TestWebhookDryRun(t *testing.T) {
+	var requests int
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	logs := captureLogs(t)
+
+	outputFile := filepath.Join(t.TempDir(), "output.jsonl")
+	w, err := NewStandardWriter(&types.Options{DryRun: true, JSONL: true, Output: outputFile})
+	require.NoError(t, err)
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "dry-run-template", Host: "https://example.com"}))
+	w.Close()
+
+	require.Equal(t, 0, requests, "requests were sent in dry-run mode")
+	require.Contains(t, logs.String(), `[dry-run] PATCH http://`)
+	require.Contains(t, logs.String(), `"status":"RUNNING"`)
+	require.Contains(t, logs.String(), `"event":"scan.started"`)
+	require.Contains(t, logs.String(), `"event":"alert"`)
+	require.Contains(t, logs.String(), `"template-id":"dry-run-template"`)
+	require.Contains(t, logs.String(), `"event":"scan.complete"`)
+
+	data, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"template-id":"dry-run-template"`)
+}
+
+func TestWebhookSlowDeliveryDoesNotBlockWrites(t *testing.T) {
+	const (
+		writers = 10
+		delay   = 200 * time.Millisecond
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+	}))
+	defer ts.Close()
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+
+	start := time.Now()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: fmt.Sprintf("template-%d", i), Host: "https://example.com"}))
+		}(i)
+	}
+	wg.Wait()
+
+	require.Less(t, time.Since(start), writers*delay/2, "writes were serialized on the webhook delivery")
+	require.Equal(t, writers, strings.Count(outputFile.String(), `"template-id":"template-`))
+}
+
+func TestWebhookSignature(t *testing.T) {
+	const secret = "webhook-secret"
+
+	type signedRequest struct {
+		event     string
+		body      []byte
+		signature string
+	}
+	var requests []signedRequest
+	setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var request AstraAlertRequest
+		_ = json.Unmarshal(body, &request)
+		requests = append(requests, signedRequest{event: request.Meta.Event, body: body, signature: r.Header.Get("X-Signature")})
+	}))
+
+	w, err := NewStandardWriter(&types.Options{JSONL: true, WebhookSecret: secret})
+	require.NoError(t, err)
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "signed"}))
+	w.Close()
+
+	require.Len(t, requests, 4)
+	for _, request := range requests {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(request.body)
+		require.Equal(t, hex.EncodeToString(mac.Sum(nil)), request.signature, "invalid signature for %s event", request.event)
+	}
+
+	t.Run("KnownBody", func(t *testing.T) {
+		// echo -n '{"meta":{},"context":{}}' | openssl dgst -sha256 -hmac webhook-secret
+		require.Equal(t, "eb27adfdc4852b80160e3008a7694513a122ce0197499f3f05c75069d6de667b", signPayload([]byte(`{"meta":{},"context":{}}`), secret))
+	})
+}
+
+func TestWebhookGzip(t *testing.T) {
+	var encoding string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	w := newTestWriter(ts.URL)
+	w.gzipThreshold = 1024
+
+	t.Run("LargePayload", func(t *testing.T) {
+		event := &ResultEvent{TemplateID: "large", ExtractedResults: []string{strings.Repeat("A", 64*1024)}}
+		require.NoError(t, w.Write(event))
+		require.Equal(t, "gzip", encoding)
+		require.Less(t, len(body), 64*1024, "payload was not compressed")
+
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		decompressed, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		var request AstraAlertRequest
+		require.NoError(t, json.Unmarshal(decompressed, &request))
+		require.Equal(t, "alert", request.Meta.Event)
+		require.Contains(t, string(request.Context), strings.Repeat("A", 64*1024))
+	})
+
+	t.Run("SmallPayload", func(t *testing.T) {
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "small"}))
+		require.Empty(t, encoding)
+
+		var request AstraAlertRequest
+		require.NoError(t, json.Unmarshal(body, &request))
+		require.Contains(t, string(request.Context), `"template-id":"small"`)
+	})
+}
+
+func TestWebhookRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	var deliveries []time.Time
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		deliveries = append(deliveries, time.Now())
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	newRateLimitedWriter := func(requestsPerSecond int) *StandardWriter {
+		w := newTestWriter(ts.URL)
+		w.httpClient.Transport = &rateLimitedTransport{
+			limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+			base:    http.DefaultTransport,
+		}
+		return w
+	}
+
+	t.Run("DeliveryRate", func(t *testing.T) {
+		w := newRateLimitedWriter(50)
+		w.alertQueue = newAlertQueue(20, 4, DropPolicyBlock, w.deliverAlert)
+
+		start := time.Now()
+		for i := 0; i < 20; i++ {
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		}
+		require.True(t, w.alertQueue.close(5*time.Second))
+		elapsed := time.Since(start)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, deliveries, 20)
+		// the first request uses the initial token, the others wait 20ms each
+		require.GreaterOrEqual(t, elapsed, 19*20*time.Millisecond*9/10, "deliveries exceeded the rate limit")
+		for i := 1; i < len(deliveries); i++ {
+			require.GreaterOrEqual(t, deliveries[i].Sub(deliveries[i-1]), 10*time.Millisecond, "deliveries exceeded the rate limit")
+		}
+	})
+
+	t.Run("Cancellation", func(t *testing.T) {
+		w := newRateLimitedWriter(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		w.deliveryCtx = ctx
+
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := w.postWebhook(w.deliveryContext(), ts.URL, []byte(`{}`), "")
+		require.ErrorIs(t, err, context.Canceled)
+		require.Less(t, time.Since(start), 500*time.Millisecond, "rate limit wait was not cancelled")
+	})
+}
+
+// newTestWriter returns a writer delivering alerts to the webhook url
+// without retry delays, suitable for testing.
+func TestStandardWriterAuthHeader(t *testing.T) {
+	type request struct {
+		method string
+		header string
+		token  interface{}
+	}
+	var mu sync.Mutex
+	var requests []request
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Meta map[string]interface{} `json:"meta"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, request{method: r.Method, header: r.Header.Get("X-Api-Key") + r.Header.Get("Authorization"), token: body.Meta["webhookToken"]})
+		mu.Unlock()
+	})
+
+	tests := []struct {
+		name      string
+		options   *types.Options
+		header    string
+		bodyToken interface{}
+	}{
+		{"Disabled", &types.Options{}, "", "webhook-token"},
+		{"Bearer", &types.Options{WebhookAuthHeader: "Authorization", WebhookAuthScheme: "Bearer"}, "Bearer webhook-token", "webhook-token"},
+		{"CustomHeader", &types.Options{WebhookAuthHeader: "X-Api-Key"}, "webhook-token", "webhook-token"},
+		{"OmitBodyToken", &types.Options{WebhookAuthHeader: "Authorization", WebhookAuthScheme: "Token", WebhookOmitBodyToken: true}, "Token webhook-token", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setAstraEnv(t, handler)
+			requests = nil
+			w, err := NewStandardWriter(test.options)
+			require.NoError(t, err)
+			require.NoError(t, w.Write(&ResultEvent{TemplateID: "test"}))
+			w.Close()
+
+			// status change, scan.started, alert, scan.summary, status change and scan.complete
+			require.Len(t, requests, 6)
+			for _, request := range requests {
+				require.Equal(t, test.header, request.header, request.method)
+				if request.method == http.MethodPost {
+					require.Equal(t, test.bodyToken, request.token)
+				}
+			}
+		})
+	}
+
+	t.Run("OmitBodyTokenWithoutHeader", func(t *testing.T) {
+		setAstraEnv(t, handler)
+		_, err := NewStandardWriter(&types.Options{WebhookOmitBodyToken: true})
+		require.ErrorContains(t, err, "without an auth header")
+	})
+}
+
+func TestWebhookBackupURL(t *testing.T) {
+	var primaryHits, backupHits atomic.Int32
+	var received AstraAlertRequest
+	primary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		backupHits.Add(1)
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer backup.Close()
+
+	t.Run("PrimaryDown", func(t *testing.T) {
+		w := newTestWriter(primary.URL)
+		w.backupWebhook = backup.URL
+		w.webhookRetries = 1
+		w.webhookRetryDelay = time.Millisecond
+
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "backup-test", Host: "https://example.com"}))
+		require.Equal(t, int32(2), primaryHits.Load(), "primary wasn't retried before falling back")
+		require.Equal(t, int32(1), backupHits.Load())
+		require.Equal(t, "alert", received.Meta.Event)
+		require.Contains(t, string(received.Context), "backup-test")
+
+		metrics := w.Metrics()
+		require.Equal(t, uint64(1), metrics.AlertsSent)
+		require.Equal(t, uint64(1), metrics.BackupAlertsSent)
+	})
+
+	t.Run("PrimaryUnreachable", func(t *testing.T) {
+		backupHits.Store(0)
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		w := newTestWriter(unreachable.URL)
+		w.backupWebhook = backup.URL
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "backup-test"}))
+		require.Equal(t, int32(1), backupHits.Load())
+		require.Equal(t, uint64(1), w.Metrics().BackupAlertsSent)
+	})
+
+	t.Run("PrimaryUp", func(t *testing.T) {
+		backupHits.Store(0)
+		w := newTestWriter(backup.URL)
+		w.backupWebhook = primary.URL
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "backup-test"}))
+		require.Equal(t, int32(1), backupHits.Load())
+		require.Equal(t, uint64(0), w.Metrics().BackupAlertsSent)
+	})
+
+	t.Run("InvalidBackupURL", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{BackupWebhookURL: "ftp://example.com/webhook"})
+		require.ErrorContains(t, err, "invalid backup webhook url")
+	})
+}
+
+func TestWebhookSeverityRouting(t *testing.T) {
+	newServer := func(templates *[]string) *httptest.Server {
+		var mu sync.Mutex
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Context ResultEvent `json:"context"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			*templates = append(*templates, body.Context.TemplateID)
+			mu.Unlock()
+		}))
+		t.Cleanup(ts.Close)
+		return ts
+	}
+	var defaultTemplates, criticalTemplates, lowTemplates []string
+	defaultServer := newServer(&defaultTemplates)
+	criticalServer := newServer(&criticalTemplates)
+	lowServer := newServer(&lowTemplates)
+
+	w := newTestWriter(defaultServer.URL)
+	w.severityWebhooks = map[severity.Severity]string{severity.Critical: criticalServer.URL, severity.Low: lowServer.URL}
+	for _, event := range []*ResultEvent{
+		{TemplateID: "log4j-rce", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Critical}}},
+		{TemplateID: "missing-headers", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Low}}},
+		{TemplateID: "git-config", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Medium}}},
+	} {
+		require.NoError(t, w.Write(event))
+	}
+	require.Equal(t, []string{"log4j-rce"}, criticalTemplates)
+	require.Equal(t, []string{"missing-headers"}, lowTemplates)
+	require.Equal(t, []string{"git-config"}, defaultTemplates, "unrouted severities weren't sent to the default webhook")
+
+	t.Run("NotBatched", func(t *testing.T) {
+		criticalTemplates, defaultTemplates = nil, nil
+		w := newTestWriter(defaultServer.URL)
+		w.severityWebhooks = map[severity.Severity]string{severity.Critical: criticalServer.URL}
+		w.alertBatcher = newAlertBatcher(0, w.dispatchAlert)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "log4j-rce", Host: "example.com", Info: model.Info{SeverityHolder: severity.Holder{Severity: severity.Critical}}}))
+		require.Equal(t, []string{"log4j-rce"}, criticalTemplates, "routed alert was batched")
+		require.Empty(t, defaultTemplates)
+	})
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		_, err := NewStandardWriter(&types.Options{SeverityWebhooks: map[severity.Severity]string{severity.High: "ftp://example.com/webhook"}})
+		require.ErrorContains(t, err, "invalid high webhook url")
+	})
+}
+
+func TestWebhookExcludeSeverities(t *testing.T) {
+	var mu sync.Mutex
+	var alerted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Context ResultEvent `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		alerted = append(alerted, body.Context.TemplateID)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	events := func() []*ResultEvent {
+		var events []*ResultEvent
+		for _, value := range []severity.Severity{severity.Info, severity.Low, severity.High} {
+			events = append(events, &ResultEvent{TemplateID: value.String() + "-template", Info: model.Info{SeverityHolder: severity.Holder{Severity: value}}})
+		}
+		return events
+	}
+
+	outputFile := &testWriteCloser{}
+	w := newTestWriter(ts.URL)
+	w.outputFile = outputFile
+	w.excludedSeverities = map[severity.Severity]struct{}{severity.Info: {}}
+	for _, event := range events() {
+		require.NoError(t, w.Write(event))
+	}
+	require.Equal(t, []string{"low-template", "high-template"}, alerted)
+	require.Contains(t, outputFile.String(), "info-template", "excluded results weren't written to the output")
+
+	t.Run("Output", func(t *testing.T) {
+		alerted = nil
+		outputFile := &testWriteCloser{}
+		w := newTestWriter(ts.URL)
+		w.outputFile = outputFile
+		w.excludedSeverities = map[severity.Severity]struct{}{severity.Info: {}}
+		w.excludeFromOutput = true
+		for _, event := range events() {
+			require.NoError(t, w.Write(event))
+		}
+		require.Equal(t, []string{"low-template", "high-template"}, alerted)
+		require.NotContains(t, outputFile.String(), "info-template")
+		require.Contains(t, outputFile.String(), "high-template")
+	})
+
+	t.Run("SeverityOverride", func(t *testing.T) {
+		alerted = nil
+		w := newTestWriter(ts.URL)
+		w.excludedSeverities = map[severity.Severity]struct{}{severity.Info: {}}
+		w.severityOverrides = map[string]severity.Severity{"low-template": severity.Info}
+		for _, event := range events() {
+			require.NoError(t, w.Write(event))
+		}
+		require.Equal(t, []string{"high-template"}, alerted, "the overridden severity wasn't excluded")
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		w, err := NewStandardWriter(&types.Options{AlertExcludeSeverities: severity.Severities{severity.Info, severity.Unknown}})
+		require.NoError(t, err)
+		require.Len(t, w.excludedSeverities, 2)
+	})
+}
+
+// recordingTransport is a http transport recording the requests in-memory
+// and answering them with an empty response
+type recordingTransport struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request := req.Method + " " + req.URL.String()
+	if req.Body != nil {
+		var body struct {
+			StateChange json.RawMessage `json:"state_change"`
+			Meta        AstraMeta       `json:"meta"`
+		}
+		data, _ := io.ReadAll(req.Body)
+		_ = json.Unmarshal(data, &body)
+		if body.StateChange != nil {
+			request += " " + string(body.StateChange)
+		} else {
+			request += " " + body.Meta.Event
+		}
+	}
+	t.mu.Lock()
+	t.requests = append(t.requests, request)
+	t.mu.Unlock()
+	return noopTransport{}.RoundTrip(req)
+}
+
+func TestWebhookTransport(t *testing.T) {
+	env := map[string]string{
+		"auditId":           "audit-id",
+		"jobId":             "job-id",
+		"scanId":            "scan-id",
+		"webhookToken":      "webhook-token",
+		"webhookUrl":        "http://webhook.example.com/alerts",
+		"DAST_API_SVC_NAME": "api.example.com",
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	transport := &recordingTransport{}
+	w, err := NewStandardWriter(&types.Options{JSONL: true, WebhookTransport: transport})
+	require.NoError(t, err)
+	require.Empty(t, transport.requests, "requests were made before the first result")
+
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+	require.Equal(t, []string{
+		`PATCH http://api.example.com/api/nuclei/scan-id {"pid":"15","status":"RUNNING"}`,
+		"POST http://webhook.example.com/alerts scan.started",
+		"POST http://webhook.example.com/alerts alert",
+	}, transport.requests)
+
+	transport.requests = nil
+	w.Close()
+	require.Equal(t, []string{
+		"POST http://webhook.example.com/alerts scan.summary",
+		`PATCH http://api.example.com/api/nuclei/scan-id {"status":"COMPLETE"}`,
+		"POST http://webhook.example.com/alerts scan.complete",
+	}, transport.requests)
+}
+
+// bodyTrackingTransport counts the response bodies left open
+type bodyTrackingTransport struct {
+	open atomic.Int64
+}
+
+type trackedBody struct {
+	io.Reader
+	transport *bodyTrackingTransport
+	once      sync.Once
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(func() { b.transport.open.Add(-1) })
+	return nil
+}
+
+func (t *bodyTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.open.Add(1)
+	body := &trackedBody{Reader: strings.NewReader(""), transport: t}
+	return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: body, Header: make(http.Header), Request: req}, nil
+}
+
+func TestWebhookResponsesClosed(t *testing.T) {
+	for key, value := range map[string]string{
+		"auditId":           "audit-id",
+		"jobId":             "job-id",
+		"scanId":            "scan-id",
+		"webhookToken":      "webhook-token",
+		"webhookUrl":        "http://webhook.example.com/alerts",
+		"DAST_API_SVC_NAME": "api.example.com",
+	} {
+		t.Setenv(key, value)
+	}
+
+	transport := &bodyTrackingTransport{}
+	w, err := NewStandardWriter(&types.Options{JSONL: true, WebhookTransport: transport})
+	require.NoError(t, err)
+	w.Start()
+	require.NoError(t, w.Write(&ResultEvent{TemplateID: "git-config"}))
+	w.Close()
+	require.Equal(t, int64(0), transport.open.Load(), "response bodies were left open")
+}
+
+func TestWebhookUnixSocket(t *testing.T) {
+	// unix socket paths are limited to ~100 bytes, t.TempDir can be longer
+	dir, err := os.MkdirTemp("", "nuclei")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "alerts.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	var received AstraAlertRequest
+	var requestPath string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	ts.Listener = listener
+	ts.Start()
+	defer ts.Close()
+
+	t.Run("Delivery", func(t *testing.T) {
+		w := newTestWriter("unix://" + socketPath)
+		w.httpClient.Transport = &unixSocketTransport{}
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "unix-socket", Host: "https://example.com"}))
+
+		require.Equal(t, "/", requestPath)
+		require.Equal(t, "alert", received.Meta.Event)
+		require.Contains(t, string(received.Context), `"template-id":"unix-socket"`)
+		require.Equal(t, uint64(1), w.Metrics().AlertsSent)
+	})
+
+	t.Run("NewStandardWriter", func(t *testing.T) {
+		setAstraEnv(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+		t.Setenv("webhookUrl", "unix://"+socketPath)
+		received = AstraAlertRequest{}
+
+		w, err := NewStandardWriter(&types.Options{})
+		require.NoError(t, err)
+		require.NoError(t, w.Write(&ResultEvent{TemplateID: "unix-socket"}))
+		require.Equal(t, "alert", received.Meta.Event)
+		w.Close()
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		require.NoError(t, validateWebhookURL("unix:///var/run/alerts.sock"))
+		require.Error(t, validateWebhookURL("unix://"))
+		require.Error(t, validateWebhookURL("unix://host/alerts.sock"))
+	})
+}
+
+func newTestWriter(webhook string) *StandardWriter {
+	auroraColorizer := aurora.NewAurora(false)
+	w := &StandardWriter{
+		json:           true,
+		aurora:         auroraColorizer,
+		severityColors: colorizer.New(auroraColorizer),
+		mutex:          &sync.Mutex{},
+		AstraWebhook:   webhook,
+		httpClient:     &http.Client{},
+	}
+	// there is no status api to start the scan against
+	w.startOnce.Do(func() {})
+	return w
+}
+
+// logCapture is a gologger writer capturing the written logs
+type logCapture struct {
+	mu      sync.Mutex
+	builder strings.Builder
+}
+
+// captureLogs captures the gologger logs for the duration of the test
+func captureLogs(t *testing.T) *logCapture {
+	capture := &logCapture{}
+	gologger.DefaultLogger.SetWriter(capture)
+	gologger.DefaultLogger.SetMaxLevel(levels.LevelVerbose)
+	t.Cleanup(func() {
+		gologger.DefaultLogger.SetWriter(writer.NewCLI())
+		gologger.DefaultLogger.SetMaxLevel(levels.LevelInfo)
+	})
+	return capture
+}
+
+func (c *logCapture) Write(data []byte, level levels.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.builder.Write(data)
+	c.builder.WriteString("\n")
+}
+
+func (c *logCapture) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.builder.String()
+}
@@ -117,6 +117,9 @@ func NewMockOutputWriter() *MockOutputWriter {
 // Close closes the output writer interface
 func (m *MockOutputWriter) Close() {}
 
+// Flush writes out any buffered output and pending alerts
+func (m *MockOutputWriter) Flush() error { return nil }
+
 // Colorizer returns the colorizer instance for writer
 func (m *MockOutputWriter) Colorizer() aurora.Aurora {
 	return m.aurora
@@ -137,6 +140,9 @@ func (m *MockOutputWriter) Request(templateID, url, requestType string, err erro
 	}
 }
 
+// WriteError writes an error encountered for a template and input to the error log
+func (m *MockOutputWriter) WriteError(templateID, input string, err error) {}
+
 // WriteFailure writes the event to file and/or screen.
 func (m *MockOutputWriter) WriteFailure(result output.InternalEvent) error {
 	return nil
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/projectdiscovery/goflags"
@@ -352,6 +353,169 @@ type Options struct {
 	FuzzingType string
 	// Fuzzing Mode overrides template level fuzzing-mode configuration
 	FuzzingMode string
+	// WebhookErrors forwards errors written to the error log as scan.error webhook events
+	WebhookErrors bool
+	// WebhookRetries is the number of times a failed webhook delivery is retried
+	WebhookRetries int
+	// DryRun logs the webhook and status api payloads instead of sending them
+	DryRun bool
+	// WebhookQueueSize is the size of the queue of alerts delivered asynchronously (0 delivers synchronously)
+	WebhookQueueSize int
+	// WebhookWorkers is the number of workers delivering queued alerts
+	WebhookWorkers int
+	// WebhookDropPolicy is the policy applied when the alert queue is full (block, drop-oldest, drop-newest)
+	WebhookDropPolicy string
+	// WebhookDrainTimeout is the maximum time to wait for queued alerts to be delivered on close
+	WebhookDrainTimeout time.Duration
+	// WebhookSecret is the secret used to sign webhook payloads with HMAC-SHA256
+	WebhookSecret string
+	// WebhookGzipThreshold is the payload size in bytes above which webhook payloads are gzip compressed (0 disables compression)
+	WebhookGzipThreshold int
+	// NormalizeMatchedAt normalizes the matched-at url of results (lowercase host, no query, resolved path)
+	NormalizeMatchedAt bool
+	// WebhookTemplate is the path to a go text/template rendering the webhook alert payload
+	WebhookTemplate string
+	// SeverityOverrides re-maps the severity of the results of the given template ids
+	SeverityOverrides map[string]severity.Severity
+	// SQLiteOutput is the sqlite database to append found results to
+	SQLiteOutput string
+	// IncludeFields is the list of top level fields to keep in the json results
+	IncludeFields goflags.StringSlice
+	// ExcludeFields is the list of top level fields to remove from the json results
+	ExcludeFields goflags.StringSlice
+	// IncludeTemplateSource includes the yaml source of the matched template in the results
+	IncludeTemplateSource bool
+	// TemplateSourceLimit is the maximum size in bytes of the included template source (0 for no limit)
+	TemplateSourceLimit int
+	// WebhookRateLimit is the maximum number of webhook and status api requests per second (0 for no limit)
+	WebhookRateLimit int
+	// ColorTheme is the theme used to color the severities (default, high-contrast, monochrome)
+	ColorTheme string
+	// ResolveIP resolves the ip address of the host of results missing it
+	ResolveIP bool
+	// CompactOutput prints results as a single [template-id] [severity] matched-at line
+	CompactOutput bool
+	// WebhookSpoolFile is the file undelivered alerts are spooled to and replayed from
+	WebhookSpoolFile string
+	// StatusMethod is the http method of the scan status change request
+	StatusMethod string
+	// StatusPathTemplate is the path of the scan status change request with {scanId} and {serviceName} placeholders
+	StatusPathTemplate string
+	// StatusScheme is the scheme of the status api service (http or https)
+	StatusScheme string
+	// StatusCABundle is a pem file of the certificate authorities trusted for the status api service
+	StatusCABundle string
+	// StatusInsecureSkipVerify disables the tls certificate verification of the status api service
+	StatusInsecureSkipVerify bool
+	// TolerateOutputErrors logs output file write errors (eg. a full disk) once and keeps scanning
+	TolerateOutputErrors bool
+	// JUnitExport is the file to write a junit report of the results to
+	JUnitExport string
+	// MaxAlertBytes is the maximum size of a webhook alert, larger alerts have their raw fields truncated (0 for no limit)
+	MaxAlertBytes int
+	// IncludeRawResponse includes the original response in the results along with the reconstructed one
+	IncludeRawResponse bool
+	// OutputRotateSize rotates the output file once it grows past the given bytes (0 disables it)
+	OutputRotateSize int
+	// OutputRotateInterval rotates the output file once it is older than the given duration (0 disables it)
+	OutputRotateInterval time.Duration
+	// WebhookAuthHeader is the header the webhook token is sent in on the webhook and status requests
+	WebhookAuthHeader string
+	// WebhookAuthScheme is the scheme prefixing the webhook token in the auth header (eg. Bearer)
+	WebhookAuthScheme string
+	// WebhookOmitBodyToken only sends the webhook token in the auth header instead of the body meta
+	WebhookOmitBodyToken bool
+	// IncludeResponseBody includes the body, decoded if chunked, in the reconstructed response of results
+	IncludeResponseBody bool
+	// WebhookBatchByHost sends the findings of a host in a single alert.batch webhook event
+	WebhookBatchByHost bool
+	// WebhookBatchSize is the maximum number of findings of a host in a batch (0 for no limit)
+	WebhookBatchSize int
+	// DisableResponseReconstruction keeps the original response in results instead of rebuilding it from its headers
+	DisableResponseReconstruction bool
+	// TraceLogFormat is the format of the trace log (jsonl or csv)
+	TraceLogFormat string
+	// BackupWebhookURL is the webhook alerts are delivered to when the delivery to the webhook fails
+	BackupWebhookURL string
+	// ExtraMeta contains custom fields added to the meta of every alert and status event
+	ExtraMeta map[string]string
+	// MetaHostname overrides the hostname sent in the meta of alerts, the machine hostname by default
+	MetaHostname string
+	// SuppressTemplateIDs is the list of template id glob patterns whose results are dropped
+	SuppressTemplateIDs goflags.StringSlice
+	// OnlyTemplateIDs is the list of template id glob patterns whose results are only written
+	OnlyTemplateIDs goflags.StringSlice
+	// PrettyJSON indents the json results written to the output file
+	PrettyJSON bool
+	// AlertFormat is the format of the webhook payloads (json or msgpack)
+	AlertFormat string
+	// SyslogTarget is the syslog results are written to (local, udp://host:port or tcp://host:port)
+	SyslogTarget string
+	// SyslogPriority is the facility.severity priority of the results written to syslog
+	SyslogPriority string
+	// MaxStoredResponsesPerKey is the maximum number of responses stored in a stored response file (0 for no limit)
+	MaxStoredResponsesPerKey int
+	// ScreenBodyLimit is the maximum size of the request and response shown in the screen output (0 for no limit)
+	ScreenBodyLimit int
+	// Stdout writes the results to stdout, decolorized unless json
+	Stdout bool
+	// MaxOpenStoredFiles is the maximum number of stored response files kept open across writes
+	MaxOpenStoredFiles int
+	// OutputFormat is the format of the json results written to stdout and the output file (json, ecs or cyclonedx)
+	OutputFormat string
+	// Fingerprint adds a hash of the fields identifying the finding to the results
+	Fingerprint bool
+	// FingerprintFields is the list of result fields hashed into the fingerprint
+	FingerprintFields goflags.StringSlice
+	// RequireStatusAPI fails the scan start if the scan state can't be changed to running
+	RequireStatusAPI bool
+	// SeverityWebhooks routes the alerts of the given severities to other webhook urls
+	SeverityWebhooks map[severity.Severity]string
+	// WebhookTransport replaces the transport of the webhook and status api requests.
+	// It is meant for tests only, to capture the requests in-memory.
+	WebhookTransport http.RoundTripper
+	// ResponseStatusLine starts the reconstructed response with a valid http status line instead of the prose preamble
+	ResponseStatusLine bool
+	// JSONOutput is the file to write the results to in JSONL format, whatever the output format
+	JSONOutput string
+	// TextOutput is the file to write the results to as decolorized text, whatever the output format
+	TextOutput string
+	// StoreResponseDateFolders nests the stored responses under a YYYY-MM-DD folder of the day they were found
+	StoreResponseDateFolders bool
+	// AlertExcludeSeverities is the list of result severities not sent to the webhook
+	AlertExcludeSeverities severity.Severities
+	// AlertExcludeOutput drops the results of the excluded alert severities from the outputs as well
+	AlertExcludeOutput bool
+	// ExtractedValues adds the extracted results keyed by their extractor name to the results
+	ExtractedValues bool
+	// DisableFileResponseReconstruction keeps the original response in the written results only
+	DisableFileResponseReconstruction bool
+	// DisableWebhookResponseReconstruction keeps the original response in the webhook alerts only
+	DisableWebhookResponseReconstruction bool
+	// AllowHosts is the list of host cidr ranges and glob patterns whose results are only written
+	AllowHosts goflags.StringSlice
+	// DenyHosts is the list of host cidr ranges and glob patterns whose results are dropped
+	DenyHosts goflags.StringSlice
+	// EnvelopeVersion is the version of the envelope wrapping the webhook events (v1 or v2)
+	EnvelopeVersion string
+	// ResponseLineEndings normalizes the line endings of the stored and reconstructed responses (lf or crlf)
+	ResponseLineEndings string
+	// MaxAlerts is the maximum number of alerts sent to the webhook for the scan, 0 for no limit
+	MaxAlerts int
+	// MaxAlertsSkipOutput drops the results over the alert quota from the output as well
+	MaxAlertsSkipOutput bool
+	// AlertTransport is the transport the alerts are delivered with (webhook or pubsub)
+	AlertTransport string
+	// PubSubTopic is the Google Cloud Pub/Sub topic the alerts are published to with the pubsub transport
+	PubSubTopic string
+	// FieldLimits truncates the request, response, raw-response, curl-command
+	// and extracted-results fields of the results to the given sizes in bytes
+	FieldLimits map[string]int
+	// AttachmentStore stores the request and response of the alerts as attachments
+	// referenced by the alerts instead of inlined (file or http)
+	AttachmentStore string
+	// AttachmentURL is the base url the attachments are uploaded to with the http attachment store
+	AttachmentURL string
 }
 
 // ShouldLoadResume resume file